@@ -0,0 +1,119 @@
+// Package ai plays the game like a player would: it chooses where each
+// piece should go and presses the buttons that take it there, one tick
+// at a time, through the same Input a keyboard produces.
+package ai
+
+import (
+	"math"
+
+	"tetris-game/engine"
+)
+
+// Bot picks a placement for every piece by scoring the board each
+// reachable placement leaves with its Weights.
+type Bot struct {
+	Weights Weights
+
+	// piece is the piece target was chosen for.
+	piece  *engine.Tetromino
+	target engine.Tetromino
+	// pressed is set after a button that acts once per press, which is
+	// released on the next tick so the following press counts.
+	pressed bool
+}
+
+func New(w Weights) *Bot {
+	return &Bot{Weights: w}
+}
+
+// Input returns the buttons to hold on the next tick of game.
+func (b *Bot) Input(game *engine.Game) engine.Input {
+	if game.IsGameOver() {
+		return 0
+	}
+	if b.pressed {
+		b.pressed = false
+		return 0
+	}
+	if game.CurrentTetromino != b.piece {
+		target, hold := b.choose(game)
+		if hold {
+			b.piece = nil
+			b.pressed = true
+			return engine.InputHold
+		}
+		b.piece, b.target = game.CurrentTetromino, target
+	}
+	current := *game.CurrentTetromino
+	m, ok := nextMove(game.Board, current, b.target)
+	if !ok {
+		// Gravity took the piece past its path; choose again from here.
+		b.target, _ = b.best(game.Board, current)
+		if m, ok = nextMove(game.Board, current, b.target); !ok {
+			m = moveNone
+		}
+	}
+	switch m {
+	case moveNone:
+		b.pressed = true
+		return engine.InputHardDrop
+	case moveDown:
+		// Soft drop moves with gravity rather than per press, so it is
+		// held until the piece is low enough.
+		return engine.InputSoftDrop
+	}
+	b.pressed = true
+	return moveInputs[m]
+}
+
+// choose returns the placement for the current piece, or reports that
+// holding it leads to a better one.
+func (b *Bot) choose(game *engine.Game) (engine.Tetromino, bool) {
+	target, score := b.best(game.Board, *game.CurrentTetromino)
+	if !game.CanHold() {
+		return target, false
+	}
+	alt, ok := game.HoldPiece()
+	if !ok {
+		next := game.Next()
+		if len(next) == 0 {
+			return target, false
+		}
+		alt = next[0]
+	}
+	_, altScore := b.best(game.Board, *engine.NewTetromino(alt))
+	return target, altScore > score
+}
+
+// toppedOut is the score of a placement that locks above the board.
+const toppedOut = -1e9
+
+// best returns the highest scoring placement of start on board and its
+// score.
+func (b *Bot) best(board engine.Board, start engine.Tetromino) (engine.Tetromino, float64) {
+	best, bestScore := start, math.Inf(-1)
+	for _, t := range placements(board, start) {
+		after := board.Copy()
+		after.Lock(&t)
+		score := b.Weights.Score(after, after.ClearLines())
+		if aboveTop(&t) {
+			score += toppedOut
+		}
+		if score > bestScore {
+			best, bestScore = t, score
+		}
+	}
+	return best, bestScore
+}
+
+// aboveTop reports whether any cell of t is above the board.
+func aboveTop(t *engine.Tetromino) bool {
+	for y, row := range t.Shape() {
+		for _, cell := range row {
+			if cell != 0 && t.Y+y < 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package ai
+
+import (
+	"testing"
+
+	"tetris-game/engine"
+)
+
+// well is four rows with the right column open, which only an I clears.
+var well = board(
+	"GGGGGGGGG.",
+	"GGGGGGGGG.",
+	"GGGGGGGGG.",
+	"GGGGGGGGG.",
+)
+
+// sequenceGame deals pieces in order on the given board.
+func sequenceGame(b engine.Board, pieces ...engine.TetrominoType) *engine.Game {
+	rules := engine.DefaultRules()
+	rules.Randomizer = engine.RandomizerSequence
+	rules.Sequence = pieces
+	game := engine.NewGame(1, rules)
+	game.Board = b.Copy()
+	return game
+}
+
+func TestChooseHold(t *testing.T) {
+	tests := []struct {
+		name string
+		game func() *engine.Game
+		want bool
+	}{
+		{"hold for the next I", func() *engine.Game {
+			return sequenceGame(well, engine.S, engine.I)
+		}, true},
+		{"keep the I", func() *engine.Game {
+			return sequenceGame(well, engine.I, engine.S)
+		}, false},
+		{"swap for the held I", func() *engine.Game {
+			game := sequenceGame(engine.NewBoard(), engine.I, engine.S, engine.S, engine.S)
+			game.Hold()
+			game.HardDrop()
+			game.Board = well.Copy()
+			return game
+		}, true},
+		{"already held this piece", func() *engine.Game {
+			game := sequenceGame(well, engine.S, engine.S, engine.I)
+			game.Hold()
+			return game
+		}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := tt.game()
+			if _, hold := New(DefaultWeights()).choose(game); hold != tt.want {
+				t.Errorf("hold %v, want %v", hold, tt.want)
+			}
+		})
+	}
+}
+
+// The bot presses the buttons that take an I into the well and a held S
+// out of the way.
+func TestBotClearsWell(t *testing.T) {
+	for _, pieces := range [][]engine.TetrominoType{
+		{engine.I},
+		{engine.S, engine.I},
+	} {
+		game := sequenceGame(well, pieces...)
+		bot := New(DefaultWeights())
+		for tick := 0; game.Lines() == 0 && tick < 10*engine.TicksPerSecond; tick++ {
+			game.Tick(bot.Input(game))
+		}
+		if game.Lines() != 4 {
+			t.Errorf("%v: cleared %d lines, want 4", pieces, game.Lines())
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package ai
+
+import "tetris-game/engine"
+
+// move is a step of a piece that one button makes.
+type move int
+
+const (
+	moveNone move = iota
+	moveLeft
+	moveRight
+	moveRotateRight
+	moveRotateLeft
+	moveDown
+)
+
+var moves = [...]move{moveLeft, moveRight, moveRotateRight, moveRotateLeft, moveDown}
+
+// moveInputs are the buttons that make each move.
+var moveInputs = [...]engine.Input{
+	moveLeft:        engine.InputLeft,
+	moveRight:       engine.InputRight,
+	moveRotateRight: engine.InputRotateRight,
+	moveRotateLeft:  engine.InputRotateLeft,
+	moveDown:        engine.InputSoftDrop,
+}
+
+// apply returns t after m on board, probing collisions the way the game
+// does, and whether the move was possible.
+func apply(board engine.Board, t engine.Tetromino, m move) (engine.Tetromino, bool) {
+	switch m {
+	case moveLeft:
+		t.X--
+	case moveRight:
+		t.X++
+	case moveDown:
+		t.Y++
+	case moveRotateRight:
+		return t, board.Rotate(&t, (t.Rotation+1)%4)
+	case moveRotateLeft:
+		return t, board.Rotate(&t, (t.Rotation+3)%4)
+	}
+	return t, !board.Collides(&t)
+}
+
+// search walks every position start can reach on board, breadth first,
+// and calls visit for each one with the move that first reached it. It
+// stops early when visit returns true.
+func search(board engine.Board, start engine.Tetromino, visit func(t engine.Tetromino, first move) bool) {
+	first := map[engine.Tetromino]move{start: moveNone}
+	queue := []engine.Tetromino{start}
+	for len(queue) > 0 {
+		t := queue[0]
+		queue = queue[1:]
+		if visit(t, first[t]) {
+			return
+		}
+		for _, m := range moves {
+			next, ok := apply(board, t, m)
+			if !ok {
+				continue
+			}
+			if _, seen := first[next]; seen {
+				continue
+			}
+			if t == start {
+				first[next] = m
+			} else {
+				first[next] = first[t]
+			}
+			queue = append(queue, next)
+		}
+	}
+}
+
+// placements returns every resting position start can reach by shifting,
+// rotating and dropping, including tucks and spins.
+func placements(board engine.Board, start engine.Tetromino) []engine.Tetromino {
+	if board.Collides(&start) {
+		return nil
+	}
+	var found []engine.Tetromino
+	search(board, start, func(t engine.Tetromino, _ move) bool {
+		if board.Drop(t) == t {
+			found = append(found, t)
+		}
+		return false
+	})
+	return found
+}
+
+// nextMove returns the first move on a shortest path from start to a
+// position that hard drops onto target, or moveNone if start already
+// does. It reports false if target cannot be reached.
+func nextMove(board engine.Board, start, target engine.Tetromino) (move, bool) {
+	found, next := false, moveNone
+	search(board, start, func(t engine.Tetromino, first move) bool {
+		if board.Drop(t) == target {
+			found, next = true, first
+		}
+		return found
+	})
+	return next, found
+}
@@ -0,0 +1,173 @@
+package ai
+
+import (
+	"slices"
+	"testing"
+
+	"tetris-game/engine"
+)
+
+// cell is a board square as (x, y).
+type cell [2]int
+
+// footprint returns the sorted cells t covers, which tells apart
+// placements that differ only in how the piece got there.
+func footprint(t engine.Tetromino) []cell {
+	var cells []cell
+	for y, row := range t.Shape() {
+		for x, c := range row {
+			if c != 0 {
+				cells = append(cells, cell{t.X + x, t.Y + y})
+			}
+		}
+	}
+	slices.SortFunc(cells, func(a, b cell) int {
+		if a[1] != b[1] {
+			return a[1] - b[1]
+		}
+		return a[0] - b[0]
+	})
+	return cells
+}
+
+func footprints(ts []engine.Tetromino) [][]cell {
+	var all [][]cell
+	for _, t := range ts {
+		f := footprint(t)
+		if !slices.ContainsFunc(all, func(g []cell) bool { return slices.Equal(f, g) }) {
+			all = append(all, f)
+		}
+	}
+	return all
+}
+
+// tuck is a ledge that only a piece sliding along the floor gets under.
+var tuck = board(
+	"GGGGGG....",
+	"..........",
+	"..........",
+)
+
+// tSlot is a T-spin double slot, roofed on the right so that only a spin
+// gets a T into it.
+var tSlot = board(
+	"GGG..GGGGG",
+	"GGG...GGGG",
+	"GGGG.GGGGG",
+)
+
+func TestPlacements(t *testing.T) {
+	tests := []struct {
+		name  string
+		board engine.Board
+		piece engine.TetrominoType
+		// count is the number of distinct resting footprints, if set.
+		count int
+		// want must be among the footprints.
+		want []cell
+	}{
+		{name: "O on an empty board", board: engine.NewBoard(), piece: engine.O, count: 9},
+		{name: "I on an empty board", board: engine.NewBoard(), piece: engine.I, count: 17},
+		{name: "T on an empty board", board: engine.NewBoard(), piece: engine.T, count: 34},
+		{
+			name: "O tucked under a ledge", board: tuck, piece: engine.O,
+			want: []cell{{0, 18}, {1, 18}, {0, 19}, {1, 19}},
+		},
+		{
+			name: "T spun into a slot", board: tSlot, piece: engine.T,
+			want: []cell{{3, 18}, {4, 18}, {5, 18}, {4, 19}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found := placements(tt.board, *engine.NewTetromino(tt.piece))
+			for _, p := range found {
+				if tt.board.Drop(p) != p {
+					t.Errorf("placement %+v is not resting", p)
+				}
+			}
+			all := footprints(found)
+			if tt.count > 0 && len(all) != tt.count {
+				t.Errorf("%d footprints, want %d", len(all), tt.count)
+			}
+			if tt.want != nil && !slices.ContainsFunc(all, func(f []cell) bool { return slices.Equal(f, tt.want) }) {
+				t.Errorf("no placement covers %v", tt.want)
+			}
+		})
+	}
+}
+
+func TestPlacementsBlocked(t *testing.T) {
+	full := engine.NewBoard()
+	for y := range full {
+		for x := range full[y] {
+			full[y][x] = engine.Garbage
+		}
+	}
+	if got := placements(full, *engine.NewTetromino(engine.T)); got != nil {
+		t.Errorf("placements on a full board: %v", got)
+	}
+}
+
+// Following nextMove from spawn must end on a position that hard drops
+// onto the target, for targets a plain drop cannot reach.
+func TestNextMove(t *testing.T) {
+	tests := []struct {
+		name  string
+		board engine.Board
+		piece engine.TetrominoType
+		want  []cell
+	}{
+		{"drop to the right wall", engine.NewBoard(), engine.I, []cell{{6, 19}, {7, 19}, {8, 19}, {9, 19}}},
+		{"tuck", tuck, engine.O, []cell{{0, 18}, {1, 18}, {0, 19}, {1, 19}}},
+		{"spin", tSlot, engine.T, []cell{{3, 18}, {4, 18}, {5, 18}, {4, 19}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := *engine.NewTetromino(tt.piece)
+			var target engine.Tetromino
+			found := false
+			for _, p := range placements(tt.board, start) {
+				if slices.Equal(footprint(p), tt.want) {
+					target, found = p, true
+					break
+				}
+			}
+			if !found {
+				t.Fatalf("no placement covers %v", tt.want)
+			}
+
+			current := start
+			for steps := 0; ; steps++ {
+				if steps > 50 {
+					t.Fatal("no path after 50 moves")
+				}
+				m, ok := nextMove(tt.board, current, target)
+				if !ok {
+					t.Fatalf("target unreachable from %+v", current)
+				}
+				if m == moveNone {
+					break
+				}
+				if current, ok = apply(tt.board, current, m); !ok {
+					t.Fatalf("move %d from %+v is blocked", m, current)
+				}
+			}
+			if got := tt.board.Drop(current); got != target {
+				t.Errorf("path ends dropping onto %+v, want %+v", got, target)
+			}
+		})
+	}
+}
+
+func TestNextMoveUnreachable(t *testing.T) {
+	// A shaft that fits an upright I, sealed under a full row.
+	sealed := board("GGGGGGGGGG", "GGGG.GGGGG", "GGGG.GGGGG", "GGGG.GGGGG", "GGGG.GGGGG")
+	target := engine.Tetromino{Type: engine.I, Rotation: engine.R90, X: 2, Y: 16}
+	if sealed.Collides(&target) || sealed.Drop(target) != target {
+		t.Fatalf("target %+v does not rest in the shaft", target)
+	}
+	if _, ok := nextMove(sealed, *engine.NewTetromino(engine.I), target); ok {
+		t.Error("found a path through a full row")
+	}
+}
@@ -0,0 +1,237 @@
+package ai
+
+import (
+	"cmp"
+	"math"
+	"math/rand"
+	"runtime"
+	"slices"
+	"sync"
+
+	"tetris-game/engine"
+)
+
+// Training configures Train.
+type Training struct {
+	// Population is how many weight vectors are evolved at once.
+	Population  int
+	Generations int
+	// Games is how many seeded games each candidate plays a generation.
+	// Every candidate of a generation plays the same seeds.
+	Games int
+	// MaxPieces stops a game that survives this many pieces.
+	MaxPieces int
+	// GarbageEvery sends a garbage row to the game every this many
+	// pieces, as an opponent would, so that weak weights top out long
+	// before MaxPieces. Zero sends none.
+	GarbageEvery int
+	// Workers is how many games run in parallel.
+	Workers int
+	// Seed makes a training run repeatable.
+	Seed  int64
+	Rules engine.Rules
+	// Progress, if set, is called after every generation with its best
+	// candidate, which is also the best so far on that generation's
+	// seeds.
+	Progress func(generation int, best Candidate)
+}
+
+// DefaultTraining is a run that improves on random weights in minutes on
+// a few cores.
+func DefaultTraining() Training {
+	rules := engine.DefaultRules()
+	rules.Gravity = engine.GravityFixed
+	return Training{
+		Population:   30,
+		Generations:  10,
+		Games:        3,
+		MaxPieces:    500,
+		GarbageEvery: 4,
+		Workers:      runtime.NumCPU(),
+		Seed:         1,
+		Rules:        rules,
+	}
+}
+
+// Candidate is a weight vector and how it did on average per game.
+type Candidate struct {
+	Weights Weights `json:"weights"`
+	Pieces  float64 `json:"pieces"`
+	Lines   float64 `json:"lines"`
+}
+
+// Fitness ranks candidates: the pieces they survived and the lines they
+// cleared, garbage included.
+func (c Candidate) Fitness() float64 {
+	return c.Pieces + c.Lines
+}
+
+// Evolution parameters, as fractions of the population.
+const (
+	tournamentSize = 0.1
+	offspring      = 0.3
+	mutationRate   = 0.05
+	mutationStep   = 0.2
+)
+
+// Train evolves weight vectors by how long their games survive and how
+// many lines they clear, and returns the best one found. Each generation
+// the best pairs of random tournaments breed children, weighted toward
+// the fitter parent and sometimes mutated, which replace the weakest
+// candidates.
+func Train(t Training) Candidate {
+	rng := rand.New(rand.NewSource(t.Seed))
+	population := make([]Candidate, max(t.Population, 2))
+	for i := range population {
+		population[i].Weights = randomWeights(rng)
+	}
+	var best Candidate
+	for gen := 1; gen <= t.Generations; gen++ {
+		seeds := make([]int64, max(t.Games, 1))
+		for i := range seeds {
+			seeds[i] = rng.Int63()
+		}
+		t.evaluate(population, seeds)
+		slices.SortFunc(population, func(a, b Candidate) int {
+			return cmp.Compare(b.Fitness(), a.Fitness())
+		})
+		// Children only replace the weakest, so the last generation's
+		// best is still here, scored on the same seeds as its rivals.
+		best = population[0]
+		if t.Progress != nil {
+			t.Progress(gen, best)
+		}
+		children := max(1, int(float64(len(population))*offspring))
+		for i := range children {
+			a, b := tournament(rng, population), tournament(rng, population)
+			population[len(population)-1-i] = Candidate{Weights: crossover(rng, a, b)}
+		}
+	}
+	return best
+}
+
+// evaluate plays every candidate on seeds across t.Workers goroutines and
+// sets its averages.
+func (t Training) evaluate(population []Candidate, seeds []int64) {
+	type job struct{ candidate, seed int }
+	jobs := make(chan job)
+	results := make([][]result, len(population))
+	for i := range results {
+		results[i] = make([]result, len(seeds))
+	}
+	var wg sync.WaitGroup
+	for range max(t.Workers, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				results[j.candidate][j.seed] = t.play(population[j.candidate].Weights, seeds[j.seed])
+			}
+		}()
+	}
+	for c := range population {
+		for s := range seeds {
+			jobs <- job{c, s}
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	for i, games := range results {
+		var pieces, lines int
+		for _, r := range games {
+			pieces += r.pieces
+			lines += r.lines
+		}
+		population[i].Pieces = float64(pieces) / float64(len(seeds))
+		population[i].Lines = float64(lines) / float64(len(seeds))
+	}
+}
+
+// result is how one game went.
+type result struct {
+	pieces, lines int
+}
+
+// ticksPerPiece bounds the ticks of a game, in case the bot stalls.
+const ticksPerPiece = 10 * engine.TicksPerSecond
+
+// play runs a game of w headless, through the bot's input like any
+// frontend, sending it garbage every t.GarbageEvery pieces.
+func (t Training) play(w Weights, seed int64) result {
+	game := engine.NewGame(seed, t.Rules)
+	bot := New(w)
+	for tick := 0; !game.IsGameOver() && game.Pieces() < t.MaxPieces && tick < t.MaxPieces*ticksPerPiece; tick++ {
+		pieces := game.Pieces()
+		game.Tick(bot.Input(game))
+		game.Events()
+		if n := game.Pieces(); t.GarbageEvery > 0 && n != pieces && n%t.GarbageEvery == 0 {
+			game.ReceiveGarbage(1)
+		}
+	}
+	return result{pieces: game.Pieces(), lines: game.Lines()}
+}
+
+// tournament returns the fittest of a random sample of population.
+func tournament(rng *rand.Rand, population []Candidate) Candidate {
+	n := max(2, int(float64(len(population))*tournamentSize))
+	best := population[rng.Intn(len(population))]
+	for range n - 1 {
+		if c := population[rng.Intn(len(population))]; c.Fitness() > best.Fitness() {
+			best = c
+		}
+	}
+	return best
+}
+
+// crossover averages the weights of a and b in proportion to their
+// fitness and sometimes nudges one weight.
+func crossover(rng *rand.Rand, a, b Candidate) Weights {
+	va, vb := a.Weights.vector(), b.Weights.vector()
+	fa, fb := a.Fitness(), b.Fitness()
+	if fa+fb == 0 {
+		fa, fb = 1, 1
+	}
+	var child [numWeights]float64
+	for i := range child {
+		child[i] = va[i]*fa + vb[i]*fb
+	}
+	if rng.Float64() < mutationRate {
+		child[rng.Intn(numWeights)] += (rng.Float64()*2 - 1) * mutationStep
+	}
+	return fromVector(normalize(child))
+}
+
+func randomWeights(rng *rand.Rand) Weights {
+	var v [numWeights]float64
+	for i := range v {
+		v[i] = rng.Float64()*2 - 1
+	}
+	return fromVector(normalize(v))
+}
+
+// numWeights is the number of fields of Weights.
+const numWeights = 5
+
+func (w Weights) vector() [numWeights]float64 {
+	return [numWeights]float64{w.Height, w.Lines, w.Holes, w.Bumpiness, w.Wells}
+}
+
+func fromVector(v [numWeights]float64) Weights {
+	return Weights{Height: v[0], Lines: v[1], Holes: v[2], Bumpiness: v[3], Wells: v[4]}
+}
+
+// normalize scales v to unit length; only the direction of a weight
+// vector changes which placement wins.
+func normalize(v [numWeights]float64) [numWeights]float64 {
+	norm := 0.0
+	for _, x := range v {
+		norm += x * x
+	}
+	if norm = math.Sqrt(norm); norm == 0 {
+		return v
+	}
+	for i := range v {
+		v[i] /= norm
+	}
+	return v
+}
@@ -0,0 +1,128 @@
+package ai
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func length(v [numWeights]float64) float64 {
+	sum := 0.0
+	for _, x := range v {
+		sum += x * x
+	}
+	return math.Sqrt(sum)
+}
+
+func near(a, b [numWeights]float64) bool {
+	for i := range a {
+		if math.Abs(a[i]-b[i]) > 1e-9 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestNormalize(t *testing.T) {
+	fifth := 1 / math.Sqrt(5)
+	tests := []struct {
+		in, want [numWeights]float64
+	}{
+		{[numWeights]float64{3, 4}, [numWeights]float64{0.6, 0.8}},
+		{[numWeights]float64{0, 0, -2}, [numWeights]float64{0, 0, -1}},
+		{[numWeights]float64{1, 1, 1, 1, 1}, [numWeights]float64{fifth, fifth, fifth, fifth, fifth}},
+		{[numWeights]float64{}, [numWeights]float64{}},
+	}
+	for _, tt := range tests {
+		if got := normalize(tt.in); !near(got, tt.want) {
+			t.Errorf("normalize(%v) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+	rng := rand.New(rand.NewSource(1))
+	for range 100 {
+		if n := length(randomWeights(rng).vector()); math.Abs(n-1) > 1e-9 {
+			t.Fatalf("random weights have length %v", n)
+		}
+	}
+}
+
+func TestTournament(t *testing.T) {
+	// With 20 candidates the sample is two, so the weakest can only win
+	// against itself.
+	population := make([]Candidate, 20)
+	for i := range population {
+		population[i].Pieces = float64(i)
+	}
+	for seed := range int64(200) {
+		rng := rand.New(rand.NewSource(seed))
+		a := population[rng.Intn(len(population))]
+		b := population[rng.Intn(len(population))]
+		want := max(a.Fitness(), b.Fitness())
+		if got := tournament(rand.New(rand.NewSource(seed)), population); got.Fitness() != want {
+			t.Fatalf("seed %d: tournament of %v and %v picked %v", seed, a.Pieces, b.Pieces, got.Pieces)
+		}
+	}
+}
+
+func TestCrossover(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b Candidate
+		want [numWeights]float64
+	}{
+		{"fitter parent weighs more",
+			Candidate{Weights: Weights{Height: 1}, Pieces: 3},
+			Candidate{Weights: Weights{Lines: 1}, Pieces: 1},
+			normalize([numWeights]float64{3, 1})},
+		{"unfit parents weigh the same",
+			Candidate{Weights: Weights{Height: 1}},
+			Candidate{Weights: Weights{Lines: 1}},
+			normalize([numWeights]float64{1, 1})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var kept, mutated int
+			for seed := range int64(200) {
+				child := crossover(rand.New(rand.NewSource(seed)), tt.a, tt.b).vector()
+				if n := length(child); math.Abs(n-1) > 1e-9 {
+					t.Fatalf("seed %d: child has length %v", seed, n)
+				}
+				if rand.New(rand.NewSource(seed)).Float64() < mutationRate {
+					mutated++
+					continue
+				}
+				kept++
+				if !near(child, tt.want) {
+					t.Fatalf("seed %d: child %v, want %v", seed, child, tt.want)
+				}
+			}
+			if kept == 0 || mutated == 0 {
+				t.Errorf("%d children kept, %d mutated", kept, mutated)
+			}
+		})
+	}
+}
+
+func TestTrainRepeatable(t *testing.T) {
+	training := DefaultTraining()
+	training.Population = 4
+	training.Generations = 2
+	training.Games = 1
+	training.MaxPieces = 20
+	training.Workers = 2
+	var generations int
+	training.Progress = func(int, Candidate) { generations++ }
+	first := Train(training)
+	if generations != 2 {
+		t.Errorf("progress called %d times, want 2", generations)
+	}
+	if n := length(first.Weights.vector()); math.Abs(n-1) > 1e-9 {
+		t.Errorf("best weights have length %v", n)
+	}
+	if first.Pieces == 0 {
+		t.Error("best candidate placed no pieces")
+	}
+	if second := Train(training); second != first {
+		t.Errorf("same seed trained %+v, then %+v", first, second)
+	}
+}
@@ -0,0 +1,116 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"tetris-game/engine"
+)
+
+// Weights scale the features of a board; the placement whose board scores
+// highest is played. Features that make a board worse carry negative
+// weights.
+type Weights struct {
+	Height    float64 `json:"height"`    // sum of the column heights
+	Lines     float64 `json:"lines"`     // rows the placement cleared
+	Holes     float64 `json:"holes"`     // empty cells under a filled one
+	Bumpiness float64 `json:"bumpiness"` // height differences of neighbors
+	Wells     float64 `json:"wells"`     // depth of columns lower than both neighbors
+}
+
+// DefaultWeights play well enough to survive for a long time at low
+// gravity. Use Load for weights tuned by training.
+func DefaultWeights() Weights {
+	return Weights{
+		Height:    -0.510066,
+		Lines:     0.760666,
+		Holes:     -0.35663,
+		Bumpiness: -0.184483,
+		Wells:     -0.1,
+	}
+}
+
+// LoadWeights reads weights saved by Save.
+func LoadWeights(path string) (Weights, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Weights{}, err
+	}
+	var w Weights
+	if err := json.Unmarshal(data, &w); err != nil {
+		return Weights{}, fmt.Errorf("ai: %s: %w", path, err)
+	}
+	return w, nil
+}
+
+// Load returns a bot playing the weights saved at path, or the default
+// weights if path is empty.
+func Load(path string) (*Bot, error) {
+	if path == "" {
+		return New(DefaultWeights()), nil
+	}
+	w, err := LoadWeights(path)
+	if err != nil {
+		return nil, err
+	}
+	return New(w), nil
+}
+
+// Save writes w to path as JSON.
+func (w Weights) Save(path string) error {
+	data, err := json.MarshalIndent(w, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// Score rates board after a placement that cleared lines rows.
+func (w Weights) Score(board engine.Board, lines int) float64 {
+	var heights [engine.BoardWidth]int
+	holes := 0
+	for x := range heights {
+		for y := 0; y < engine.BoardHeight; y++ {
+			if board[y][x] == engine.Empty {
+				if heights[x] > 0 {
+					holes++
+				}
+				continue
+			}
+			if heights[x] == 0 {
+				heights[x] = engine.BoardHeight - y
+			}
+		}
+	}
+	height, bumpiness, wells := 0, 0, 0
+	for x, h := range heights {
+		height += h
+		if x > 0 {
+			bumpiness += abs(h - heights[x-1])
+		}
+		// The walls count as higher than any column.
+		left, right := engine.BoardHeight, engine.BoardHeight
+		if x > 0 {
+			left = heights[x-1]
+		}
+		if x < len(heights)-1 {
+			right = heights[x+1]
+		}
+		if depth := min(left, right) - h; depth > 0 {
+			wells += depth
+		}
+	}
+	return w.Height*float64(height) +
+		w.Lines*float64(lines) +
+		w.Holes*float64(holes) +
+		w.Bumpiness*float64(bumpiness) +
+		w.Wells*float64(wells)
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
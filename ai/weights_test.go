@@ -0,0 +1,69 @@
+package ai
+
+import (
+	"testing"
+
+	"tetris-game/engine"
+)
+
+// board decodes rows as written by engine.EncodeBoard, 'G' for filled and
+// '.' for empty. They are the bottom of the board; the rows above are
+// empty.
+func board(rows ...string) engine.Board {
+	full := make([]string, engine.BoardHeight-len(rows), engine.BoardHeight)
+	return engine.DecodeBoard(append(full, rows...))
+}
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		name  string
+		board engine.Board
+		lines int
+		// The features of the board, each scored alone with a unit
+		// weight.
+		height, holes, bumpiness, wells int
+	}{
+		{name: "empty", board: engine.NewBoard()},
+		{name: "lines", board: engine.NewBoard(), lines: 3},
+		{
+			name:  "flat row with a gap",
+			board: board("GGGGG.GGGG"),
+			// The gap is a well one deep between columns one high.
+			height: 9, bumpiness: 2, wells: 1,
+		},
+		{
+			name: "steps, a hole and two wells",
+			board: board(
+				"G.........",
+				"G.G.G.....",
+				"GGG.......",
+			),
+			height: 8, holes: 1, bumpiness: 9, wells: 3,
+		},
+		{
+			name:  "covered column",
+			board: board("GGG", "...", "..."),
+			// Each of the three columns is three high over two holes.
+			height: 9, holes: 6, bumpiness: 3, wells: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, f := range []struct {
+				name string
+				w    Weights
+				want int
+			}{
+				{"height", Weights{Height: 1}, tt.height},
+				{"lines", Weights{Lines: 1}, tt.lines},
+				{"holes", Weights{Holes: 1}, tt.holes},
+				{"bumpiness", Weights{Bumpiness: 1}, tt.bumpiness},
+				{"wells", Weights{Wells: 1}, tt.wells},
+			} {
+				if got := f.w.Score(tt.board, tt.lines); got != float64(f.want) {
+					t.Errorf("%s %v, want %d", f.name, got, f.want)
+				}
+			}
+		})
+	}
+}
@@ -1,266 +1,131 @@
 package main
 
 import (
+	"flag"
+	"fmt"
+	"log"
+
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
-	"image/color"
-	"log"
-	"math/rand"
-	"time"
+
+	"tetris-game/cli"
+	"tetris-game/engine"
+	"tetris-game/highscore"
+	"tetris-game/render"
+	"tetris-game/session"
 )
 
 const (
 	screenWidth  = 320
-	screenHeight = 600
+	screenHeight = 640
 	gridSize     = 25
-	boardWidth   = 10
-	boardHeight  = 20
+	previewSize  = 12
+	panelX       = engine.BoardWidth*gridSize + 5
 )
 
-type Game struct {
-	board         [boardHeight][boardWidth]int
-	currentPiece  *Piece
-	score         int
-	gameOver      bool
-	tickCount     int        // 添加这一行
-	lastMoveDown  time.Time
-	moveDownDelay time.Duration
-}
-
-type Piece struct {
-	x, y    int
-	shape   [][]int
-	current int
-}
+var (
+	rules    = engine.DefaultRules()
+	seed     int64
+	bot      cli.Bot
+	handling = engine.DefaultHandling()
+)
 
-var tetrominos = [][][]int{
-	{ // I
-		{1, 1, 1, 1},
-		{0, 0, 0, 0},
-		{0, 0, 0, 0},
-		{0, 0, 0, 0},
-	},
-	{ // O
-		{1, 1},
-		{1, 1},
-	},
-	{ // T
-		{0, 1, 0},
-		{1, 1, 1},
-		{0, 0, 0},
-	},
-	{ // L
-		{1, 0, 0},
-		{1, 1, 1},
-		{0, 0, 0},
-	},
-	{ // J
-		{0, 0, 1},
-		{1, 1, 1},
-		{0, 0, 0},
-	},
-	{ // S
-		{0, 1, 1},
-		{1, 1, 0},
-		{0, 0, 0},
-	},
-	{ // Z
-		{1, 1, 0},
-		{0, 1, 1},
-		{0, 0, 0},
-	},
+type Game struct {
+	session   *session.Session
+	announcer render.Announcer
 }
 
 func NewGame() *Game {
-	g := &Game{
-		moveDownDelay: time.Millisecond * 500,
-		lastMoveDown:  time.Now(),
-	}
-	g.spawnPiece()
-	return g
-}
-
-func (g *Game) spawnPiece() {
-	shapeIdx := rand.Intn(len(tetrominos))
-	shape := tetrominos[shapeIdx]
-	g.currentPiece = &Piece{
-		x:     boardWidth/2 - len(shape[0])/2,
-		y:     0,
-		shape: shape,
-	}
-	
-	if !g.isValidMove(g.currentPiece.x, g.currentPiece.y, g.currentPiece.shape) {
-		g.gameOver = true
-	}
-}
-
-func (g *Game) isValidMove(x, y int, shape [][]int) bool {
-	for i := 0; i < len(shape); i++ {
-		for j := 0; j < len(shape[i]); j++ {
-			if shape[i][j] == 0 {
-				continue
-			}
-			
-			newX := x + j
-			newY := y + i
-			
-			if newX < 0 || newX >= boardWidth || newY >= boardHeight {
-				return false
-			}
-			
-			if newY >= 0 && g.board[newY][newX] != 0 {
-				return false
-			}
+	// 高分榜保存在用户配置目录
+	scores, path, err := highscore.LoadDefault()
+	if err != nil {
+		log.Print(err)
+	}
+	// 下落速度随等级加快
+	s := session.New(rules, handling, scores, path)
+	s.Seed = seed
+	// 电脑代打
+	if bot.Play {
+		if s.Bot, err = bot.Load(); err != nil {
+			log.Fatal(err)
 		}
 	}
-	return true
+	return &Game{session: s}
 }
 
 func (g *Game) Update() error {
-	if g.gameOver {
-		if ebiten.IsKeyPressed(ebiten.KeyR) {
-			*g = *NewGame()
-		}
-		return nil
+	// 按住的键交给引擎处理，自动重复由 DAS/ARR 控制
+	var in engine.Input
+	if ebiten.IsKeyPressed(ebiten.KeyLeft) {
+		in |= engine.InputLeft
 	}
-
-	now := time.Now()
-	if now.Sub(g.lastMoveDown) >= g.moveDownDelay {
-		g.moveDown()
-		g.lastMoveDown = now
+	if ebiten.IsKeyPressed(ebiten.KeyRight) {
+		in |= engine.InputRight
 	}
-
-	// 添加按键检测的防抖
-	if g.tickCount%5 == 0 {  // 每5帧才检测一次按键，避免旋转太快
-		if ebiten.IsKeyPressed(ebiten.KeyLeft) {
-			if g.isValidMove(g.currentPiece.x-1, g.currentPiece.y, g.currentPiece.shape) {
-				g.currentPiece.x--
-			}
-		}
-		if ebiten.IsKeyPressed(ebiten.KeyRight) {
-			if g.isValidMove(g.currentPiece.x+1, g.currentPiece.y, g.currentPiece.shape) {
-				g.currentPiece.x++
-			}
-		}
-		// 添加向上箭头旋转方块的控制
-		if ebiten.IsKeyPressed(ebiten.KeyUp) {
-			g.rotatePiece()
-		}
+	// 向上箭头旋转方块
+	if ebiten.IsKeyPressed(ebiten.KeyUp) {
+		in |= engine.InputRotateRight
 	}
-
-	// 下箭头可以连续按
+	// 下箭头加速下落
 	if ebiten.IsKeyPressed(ebiten.KeyDown) {
-		g.moveDown()
+		in |= engine.InputSoftDrop
 	}
-
-	// 空格键仍然保留作为备选旋转键
+	// C 或 Shift 暂存当前方块
+	if ebiten.IsKeyPressed(ebiten.KeyC) || ebiten.IsKeyPressed(ebiten.KeyShift) {
+		in |= engine.InputHold
+	}
+	// 空格键直接落到底
 	if ebiten.IsKeyPressed(ebiten.KeySpace) {
-		g.rotatePiece()
+		in |= engine.InputHardDrop
 	}
+	// 标题、暂停、结束等画面由 session 处理
+	// 消行、T-Spin 等得分事件显示在棋盘下方
+	g.announcer.Update(g.session.Update(render.SessionInput(in)))
 
-	g.tickCount++
 	return nil
 }
 
-func (g *Game) moveDown() {
-	if g.isValidMove(g.currentPiece.x, g.currentPiece.y+1, g.currentPiece.shape) {
-		g.currentPiece.y++
-	} else {
-		g.lockPiece()
-		g.checkLines()
-		g.spawnPiece()
+func (g *Game) Draw(screen *ebiten.Image) {
+	// 暂停时隐藏棋盘
+	if g.session.BoardVisible() {
+		g.drawGame(screen, g.session.Game)
 	}
+	render.Overlay(screen, g.session, 20, 150)
 }
 
-func (g *Game) lockPiece() {
-	for i := 0; i < len(g.currentPiece.shape); i++ {
-		for j := 0; j < len(g.currentPiece.shape[i]); j++ {
-			if g.currentPiece.shape[i][j] != 0 {
-				y := g.currentPiece.y + i
-				if y >= 0 {
-					g.board[y][g.currentPiece.x+j] = 1
-				}
-			}
-		}
-	}
-}
+func (g *Game) drawGame(screen *ebiten.Image, game *engine.Game) {
+	grid := render.Grid{Size: gridSize, Gap: 1}
 
-func (g *Game) checkLines() {
-	for i := boardHeight - 1; i >= 0; i-- {
-		full := true
-		for j := 0; j < boardWidth; j++ {
-			if g.board[i][j] == 0 {
-				full = false
-				break
-			}
-		}
-		if full {
-			g.score += 100
-			for k := i; k > 0; k-- {
-				copy(g.board[k][:], g.board[k-1][:])
-			}
-			for j := 0; j < boardWidth; j++ {
-				g.board[0][j] = 0
-			}
-			i++
-		}
-	}
-}
+	// Draw board, ghost and current piece (按方块种类着色)
+	grid.Board(screen, game.Board)
+	grid.Current(screen, game)
 
-func (g *Game) rotatePiece() {
-	rows := len(g.currentPiece.shape)
-	cols := len(g.currentPiece.shape[0])
-	rotated := make([][]int, cols)
-	for i := range rotated {
-		rotated[i] = make([]int, rows)
-	}
-	
-	for i := 0; i < rows; i++ {
-		for j := 0; j < cols; j++ {
-			rotated[j][rows-1-i] = g.currentPiece.shape[i][j]
-		}
-	}
-	
-	if g.isValidMove(g.currentPiece.x, g.currentPiece.y, rotated) {
-		g.currentPiece.shape = rotated
-	}
-}
+	render.Hold(screen, game, panelX, 0, previewSize)
+	render.Next(screen, game, panelX, 70, previewSize, 36)
 
-func (g *Game) Draw(screen *ebiten.Image) {
-	// Draw board
-	for i := 0; i < boardHeight; i++ {
-		for j := 0; j < boardWidth; j++ {
-			if g.board[i][j] != 0 {
-				ebitenutil.DrawRect(screen, float64(j*gridSize), float64(i*gridSize), float64(gridSize-1), float64(gridSize-1), color.RGBA{0, 255, 0, 255})
-			}
-		}
-	}
-
-	// Draw current piece
-	if g.currentPiece != nil {
-		for i := 0; i < len(g.currentPiece.shape); i++ {
-			for j := 0; j < len(g.currentPiece.shape[i]); j++ {
-				if g.currentPiece.shape[i][j] != 0 {
-					x := float64((g.currentPiece.x + j) * gridSize)
-					y := float64((g.currentPiece.y + i) * gridSize)
-					ebitenutil.DrawRect(screen, x, y, float64(gridSize-1), float64(gridSize-1), color.RGBA{255, 0, 0, 255})
-				}
-			}
-		}
-	}
+	g.announcer.Draw(screen, 5, engine.BoardHeight*gridSize+10)
+	// 竞速模式的计时和分段
+	render.Stats(screen, g.session, 150, engine.BoardHeight*gridSize+10)
 
-	if g.gameOver {
-		ebitenutil.DebugPrint(screen, "Game Over! Press R to restart")
-	} else {
-		ebitenutil.DebugPrint(screen, "Score: "+string(rune(g.score)))
-	}
+	ebitenutil.DebugPrint(screen, fmt.Sprintf("Score: %d\nLevel: %d", game.Score(), game.Level()))
 }
 
-func (g *Game) Layout(outsideWidth, outsideHeight int) (screenWidth, screenHeight int) {
-	return 320, 600
+func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
+	return screenWidth, screenHeight
 }
 
 func main() {
+	cli.RulesFlags(flag.CommandLine, &rules, &seed)
+	cli.HandlingFlags(flag.CommandLine, &handling)
+	cli.BotFlags(flag.CommandLine, &bot)
+	flag.Parse()
+	if err := rules.Validate(); err != nil {
+		log.Fatal(err)
+	}
+	if err := handling.Validate(); err != nil {
+		log.Fatal(err)
+	}
+	ebiten.SetTPS(engine.TicksPerSecond)
 	ebiten.SetWindowSize(screenWidth, screenHeight)
 	ebiten.SetWindowTitle("Tetris")
 
@@ -268,4 +133,4 @@ func main() {
 	if err := ebiten.RunGame(game); err != nil {
 		log.Fatal(err)
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,86 @@
+// Package cli holds the command-line flags shared by the tetris frontends
+// and the headless commands that both the game and tetris-headless run.
+package cli
+
+import (
+	"flag"
+	"strconv"
+	"strings"
+
+	"tetris-game/ai"
+	"tetris-game/engine"
+)
+
+// RulesFlags adds -seed, -randomizer, -sequence, -gravity, -next and
+// -messiness to fs, defaulting to the values of rules and seed. The rules
+// are not validated; call Rules.Validate after parsing.
+func RulesFlags(fs *flag.FlagSet, rules *engine.Rules, seed *int64) {
+	fs.Int64Var(seed, "seed", *seed, "seed for the piece sequence of every game; 0 picks a new one each game")
+	fs.StringVar(&rules.Randomizer, "randomizer", rules.Randomizer, "piece randomizer: bag, random, history or sequence")
+	fs.Var((*sequenceValue)(&rules.Sequence), "sequence", "pieces dealt by the sequence randomizer, e.g. IJLOSTZ")
+	fs.StringVar(&rules.Gravity, "gravity", rules.Gravity, "gravity curve: guideline, nes or fixed")
+	fs.IntVar(&rules.NextCount, "next", rules.NextCount, "number of next pieces to preview, 0-6")
+	fs.Float64Var(&rules.Messiness, "messiness", rules.Messiness, "chance from 0 to 1 that a garbage hole moves in dig mode")
+}
+
+// Bot is what the bot flags ask for.
+type Bot struct {
+	// Play lets the computer play instead of the keyboard.
+	Play bool
+	// Weights is a file written by train; empty uses the defaults.
+	Weights string
+}
+
+// BotFlags adds -ai and -ai-weights to fs.
+func BotFlags(fs *flag.FlagSet, b *Bot) {
+	fs.BoolVar(&b.Play, "ai", b.Play, "let the computer play, for demos and soak testing")
+	fs.StringVar(&b.Weights, "ai-weights", b.Weights, "weights for -ai written by train; empty uses the defaults")
+}
+
+// Load returns the bot playing b's weights.
+func (b Bot) Load() (*ai.Bot, error) {
+	return ai.Load(b.Weights)
+}
+
+// HandlingFlags adds -das, -arr and -sdf to fs, defaulting to h's values.
+func HandlingFlags(fs *flag.FlagSet, h *engine.Handling) {
+	fs.Var((*ticksValue)(&h.DAS), "das", "delayed auto shift, in ticks or with a unit such as 167ms")
+	fs.Var((*ticksValue)(&h.ARR), "arr", "auto repeat rate, in ticks or with a unit such as 33ms; 0 shifts to the wall")
+	fs.IntVar(&h.SDF, "sdf", h.SDF, "soft drop speed as a multiple of gravity")
+}
+
+// ticksValue is a flag.Value read with engine.ParseTicks.
+type ticksValue int
+
+func (v *ticksValue) String() string {
+	return strconv.Itoa(int(*v))
+}
+
+func (v *ticksValue) Set(s string) error {
+	n, err := engine.ParseTicks(s)
+	if err != nil {
+		return err
+	}
+	*v = ticksValue(n)
+	return nil
+}
+
+// sequenceValue is a flag.Value read with engine.ParseSequence.
+type sequenceValue []engine.TetrominoType
+
+func (v *sequenceValue) String() string {
+	var s strings.Builder
+	for _, t := range *v {
+		s.WriteString(t.String())
+	}
+	return s.String()
+}
+
+func (v *sequenceValue) Set(s string) error {
+	pieces, err := engine.ParseSequence(s)
+	if err != nil {
+		return err
+	}
+	*v = pieces
+	return nil
+}
@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"flag"
+	"io"
+	"slices"
+	"testing"
+
+	"tetris-game/engine"
+)
+
+func TestHandlingFlags(t *testing.T) {
+	tests := []struct {
+		args    []string
+		want    engine.Handling
+		wantErr bool
+	}{
+		{nil, engine.DefaultHandling(), false},
+		{[]string{"-das", "7", "-arr", "0", "-sdf", "40"}, engine.Handling{DAS: 7, ARR: 0, SDF: 40}, false},
+		{[]string{"-das", "167ms", "-arr", "33ms"}, engine.Handling{DAS: 10, ARR: 2, SDF: engine.DefaultSDF}, false},
+		{[]string{"-das", "soon"}, engine.DefaultHandling(), true},
+	}
+	for _, tt := range tests {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		h := engine.DefaultHandling()
+		HandlingFlags(fs, &h)
+		err := fs.Parse(tt.args)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q: error %v, want error %v", tt.args, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && h != tt.want {
+			t.Errorf("%q: got %+v, want %+v", tt.args, h, tt.want)
+		}
+	}
+}
+
+func TestRulesFlags(t *testing.T) {
+	tests := []struct {
+		args     []string
+		wantSeed int64
+		want     func(*engine.Rules)
+		wantErr  bool
+	}{
+		{nil, 0, func(*engine.Rules) {}, false},
+		{[]string{"-seed", "9", "-next", "3", "-gravity", "nes"}, 9, func(r *engine.Rules) {
+			r.NextCount = 3
+			r.Gravity = engine.GravityNES
+		}, false},
+		{[]string{"-randomizer", "sequence", "-sequence", "TTI"}, 0, func(r *engine.Rules) {
+			r.Randomizer = engine.RandomizerSequence
+			r.Sequence = []engine.TetrominoType{engine.T, engine.T, engine.I}
+		}, false},
+		{[]string{"-sequence", "TX"}, 0, nil, true},
+	}
+	for _, tt := range tests {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		rules := engine.DefaultRules()
+		var seed int64
+		RulesFlags(fs, &rules, &seed)
+		err := fs.Parse(tt.args)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q: error %v, want error %v", tt.args, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		want := engine.DefaultRules()
+		tt.want(&want)
+		if seed != tt.wantSeed || rules.NextCount != want.NextCount || rules.Gravity != want.Gravity ||
+			rules.Randomizer != want.Randomizer || !slices.Equal(rules.Sequence, want.Sequence) {
+			t.Errorf("%q: got seed %d and %+v, want %d and %+v", tt.args, seed, rules, tt.wantSeed, want)
+		}
+	}
+}
+
+func TestBotFlags(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	var bot Bot
+	BotFlags(fs, &bot)
+	if err := fs.Parse([]string{"-ai", "-ai-weights", "w.json"}); err != nil {
+		t.Fatal(err)
+	}
+	if want := (Bot{Play: true, Weights: "w.json"}); bot != want {
+		t.Errorf("got %+v, want %+v", bot, want)
+	}
+}
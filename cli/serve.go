@@ -0,0 +1,26 @@
+package cli
+
+import (
+	"flag"
+	"log"
+
+	"tetris-game/engine"
+	"tetris-game/netplay"
+)
+
+// Serve runs the serve command with its command-line arguments: a
+// headless server that pairs the clients joining it into matches.
+func Serve(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	rules := engine.DefaultRules()
+	var seed int64
+	addr := fs.String("addr", ":"+netplay.DefaultPort, "TCP address to listen on")
+	RulesFlags(fs, &rules, &seed)
+	fs.Parse(args)
+	noArgs(fs)
+	if err := rules.Validate(); err != nil {
+		log.Fatal(err)
+	}
+	server := &netplay.Server{Rules: rules, Seed: seed}
+	log.Fatal(server.ListenAndServe(*addr))
+}
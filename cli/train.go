@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"tetris-game/ai"
+)
+
+// Train runs the train command with its command-line arguments: it
+// evolves bot weights headless and writes the best to a JSON file for
+// -ai-weights.
+func Train(args []string) {
+	fs := flag.NewFlagSet("train", flag.ExitOnError)
+	t := ai.DefaultTraining()
+	fs.IntVar(&t.Population, "population", t.Population, "weight vectors evolved at once")
+	fs.IntVar(&t.Generations, "generations", t.Generations, "generations to evolve")
+	fs.IntVar(&t.Games, "games", t.Games, "seeded games each candidate plays per generation")
+	fs.IntVar(&t.MaxPieces, "pieces", t.MaxPieces, "pieces after which a game stops")
+	fs.IntVar(&t.GarbageEvery, "garbage", t.GarbageEvery, "pieces between garbage rows sent to each game; 0 sends none")
+	fs.IntVar(&t.Workers, "workers", t.Workers, "games played in parallel")
+	fs.Int64Var(&t.Seed, "seed", t.Seed, "seed of the games and the evolution")
+	fs.StringVar(&t.Rules.Gravity, "gravity", t.Rules.Gravity, "gravity curve: guideline, nes or fixed")
+	out := fs.String("out", "weights.json", "file the best weights are written to")
+	fs.Parse(args)
+	noArgs(fs)
+	if err := t.Rules.Validate(); err != nil {
+		log.Fatal(err)
+	}
+
+	t.Progress = func(generation int, best ai.Candidate) {
+		w := best.Weights
+		fmt.Printf("generation %d: %.1f pieces %.1f lines  height %.3f lines %.3f holes %.3f bumpiness %.3f wells %.3f\n",
+			generation, best.Pieces, best.Lines, w.Height, w.Lines, w.Holes, w.Bumpiness, w.Wells)
+		// Keep the best so far in case the run is stopped early.
+		if err := w.Save(*out); err != nil {
+			log.Fatal(err)
+		}
+	}
+	best := ai.Train(t)
+	fmt.Printf("best: %.1f pieces and %.1f lines per game of at most %d, saved to %s\n", best.Pieces, best.Lines, t.MaxPieces, *out)
+}
+
+// noArgs exits with the usage of fs if it was given arguments besides
+// flags.
+func noArgs(fs *flag.FlagSet) {
+	if fs.NArg() > 0 {
+		fmt.Fprintf(fs.Output(), "%s: unexpected argument %q\n", fs.Name(), fs.Arg(0))
+		fs.Usage()
+		os.Exit(2)
+	}
+}
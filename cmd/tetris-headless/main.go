@@ -0,0 +1,30 @@
+// Command tetris-headless runs the parts of tetris that need no display:
+// a versus server and bot training. It builds without cgo or a window
+// system.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"tetris-game/cli"
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: tetris-headless serve [flags]\n       tetris-headless train [flags]")
+	os.Exit(2)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	switch os.Args[1] {
+	case "serve":
+		cli.Serve(os.Args[2:])
+	case "train":
+		cli.Train(os.Args[2:])
+	default:
+		usage()
+	}
+}
@@ -0,0 +1,83 @@
+package engine
+
+// Guideline attack tables: the garbage lines sent for each clear, indexed
+// by rows cleared.
+var (
+	clearAttack     = [...]int{0, 0, 1, 2, 4}
+	miniTSpinAttack = [...]int{0, 0, 1}
+	tSpinAttack     = [...]int{0, 2, 4, 6}
+	// comboAttack is indexed by the combo count, the last entry repeating.
+	comboAttack = [...]int{0, 1, 1, 2, 2, 3, 3, 4, 4, 4, 5}
+)
+
+const (
+	backToBackAttack   = 1
+	perfectClearAttack = 10
+)
+
+// attackLines returns the garbage lines a lock sends before canceling.
+func (e Event) attackLines() int {
+	if e.Kind != EventLock || e.Lines == 0 {
+		return 0
+	}
+	var lines int
+	switch e.TSpin {
+	case TSpinFull:
+		lines = tSpinAttack[min(e.Lines, 3)]
+	case TSpinMini:
+		lines = miniTSpinAttack[min(e.Lines, 2)]
+	default:
+		lines = clearAttack[min(e.Lines, 4)]
+	}
+	if e.BackToBack {
+		lines += backToBackAttack
+	}
+	lines += comboAttack[min(e.Combo, len(comboAttack)-1)]
+	if e.PerfectClear {
+		lines += perfectClearAttack
+	}
+	return lines
+}
+
+// ReceiveGarbage queues an attack of lines garbage rows from an opponent.
+// They rise the next time a piece locks without clearing a row, unless
+// an attack of this game's own cancels them first.
+func (game *Game) ReceiveGarbage(lines int) {
+	if lines > 0 && !game.gameOver {
+		game.incoming = append(game.incoming, lines)
+	}
+}
+
+// PendingGarbage returns how many garbage rows are waiting to rise.
+func (game *Game) PendingGarbage() int {
+	pending := 0
+	for _, lines := range game.incoming {
+		pending += lines
+	}
+	return pending
+}
+
+// cancelGarbage spends attack on the oldest incoming garbage first and
+// returns the lines left over to send.
+func (game *Game) cancelGarbage(attack int) int {
+	for attack > 0 && len(game.incoming) > 0 {
+		n := min(attack, game.incoming[0])
+		attack -= n
+		if game.incoming[0] -= n; game.incoming[0] == 0 {
+			game.incoming = game.incoming[1:]
+		}
+	}
+	return attack
+}
+
+// riseGarbage pushes all incoming garbage into the board, each attack
+// with its own hole. It reports false if the board topped out.
+func (game *Game) riseGarbage() bool {
+	for _, lines := range game.incoming {
+		if !game.garbage.rise(game.Board, lines) {
+			return false
+		}
+	}
+	game.incoming = nil
+	return true
+}
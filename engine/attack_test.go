@@ -0,0 +1,142 @@
+package engine
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestAttackLines(t *testing.T) {
+	tests := []struct {
+		name  string
+		event Event
+		want  int
+	}{
+		{"no clear", Event{Kind: EventLock, Combo: -1}, 0},
+		{"level up", Event{Kind: EventLevelUp, Lines: 4}, 0},
+		{"single", Event{Kind: EventLock, Lines: 1}, 0},
+		{"double", Event{Kind: EventLock, Lines: 2}, 1},
+		{"triple", Event{Kind: EventLock, Lines: 3}, 2},
+		{"tetris", Event{Kind: EventLock, Lines: 4}, 4},
+		{"back-to-back tetris", Event{Kind: EventLock, Lines: 4, BackToBack: true}, 5},
+		{"mini T-spin single", Event{Kind: EventLock, Lines: 1, TSpin: TSpinMini}, 0},
+		{"T-spin double", Event{Kind: EventLock, Lines: 2, TSpin: TSpinFull}, 4},
+		{"T-spin triple", Event{Kind: EventLock, Lines: 3, TSpin: TSpinFull}, 6},
+		{"double in a 3 combo", Event{Kind: EventLock, Lines: 2, Combo: 3}, 3},
+		{"single in a long combo", Event{Kind: EventLock, Lines: 1, Combo: 20}, 5},
+		{"perfect clear double", Event{Kind: EventLock, Lines: 2, PerfectClear: true}, 11},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.event.attackLines(); got != tt.want {
+				t.Errorf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// wellGame returns a game whose I hangs over a well four rows deep, with
+// a row below it that the Tetris leaves behind.
+func wellGame(pieces ...TetrominoType) *Game {
+	game := NewGame(1, testRules(pieces...))
+	game.Board = testBoard(
+		"#########.",
+		"#########.",
+		"#########.",
+		"#########.",
+		"####.#####",
+	)
+	game.CurrentTetromino = &Tetromino{Type: I, Rotation: R90, X: 7, Y: 0}
+	return game
+}
+
+// lockEvent hard drops the current piece and returns its lock event. It
+// releases the buttons for a tick first so that the drop is a new press.
+func lockEvent(t *testing.T, game *Game) Event {
+	t.Helper()
+	game.Tick(0)
+	game.Events()
+	game.Tick(InputHardDrop)
+	for _, e := range game.Events() {
+		if e.Kind == EventLock {
+			return e
+		}
+	}
+	t.Fatal("no lock event")
+	return Event{}
+}
+
+func TestCancelGarbage(t *testing.T) {
+	tests := []struct {
+		name        string
+		incoming    []int
+		wantAttack  int
+		wantPending int
+	}{
+		{"nothing incoming", nil, 4, 0},
+		{"less incoming", []int{1, 2}, 1, 0},
+		{"as much incoming", []int{3, 1}, 0, 0},
+		{"more incoming", []int{3, 2}, 0, 1},
+		{"ignored attacks", []int{0, -2, 5}, 0, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := wellGame(I, O)
+			for _, lines := range tt.incoming {
+				game.ReceiveGarbage(lines)
+			}
+			e := lockEvent(t, game)
+			if e.Lines != 4 {
+				t.Fatalf("cleared %d lines, want 4", e.Lines)
+			}
+			if e.Attack != tt.wantAttack {
+				t.Errorf("attack %d, want %d", e.Attack, tt.wantAttack)
+			}
+			if got := game.PendingGarbage(); got != tt.wantPending {
+				t.Errorf("%d lines pending, want %d", got, tt.wantPending)
+			}
+		})
+	}
+}
+
+// Garbage left over after canceling waits through a clear and rises
+// under the next piece that clears nothing.
+func TestRiseGarbage(t *testing.T) {
+	game := wellGame(I, O)
+	game.ReceiveGarbage(6)
+	lockEvent(t, game)
+	if got := game.PendingGarbage(); got != 2 {
+		t.Fatalf("%d lines pending after the Tetris, want 2", got)
+	}
+	bottom := slices.Clone(game.Board[BoardHeight-1])
+	if e := lockEvent(t, game); e.Lines != 0 {
+		t.Fatalf("O cleared %d lines", e.Lines)
+	}
+	if got := game.PendingGarbage(); got != 0 {
+		t.Errorf("%d lines pending after the O, want 0", got)
+	}
+	if !slices.Equal(game.Board[BoardHeight-3], bottom) {
+		t.Errorf("bottom row %v did not rise two rows", bottom)
+	}
+	for y := BoardHeight - 2; y < BoardHeight; y++ {
+		empty := 0
+		for _, c := range game.Board[y] {
+			if c == Empty {
+				empty++
+			} else if c != Garbage {
+				t.Errorf("row %d holds %v", y, c)
+			}
+		}
+		if empty != 1 {
+			t.Errorf("garbage row %d has %d holes, want 1", y, empty)
+		}
+	}
+}
+
+func TestReceiveGarbageGameOver(t *testing.T) {
+	game := NewGame(1, testRules(T))
+	game.gameOver = true
+	game.ReceiveGarbage(3)
+	if got := game.PendingGarbage(); got != 0 {
+		t.Errorf("%d lines pending after the game ended", got)
+	}
+}
@@ -0,0 +1,228 @@
+package engine
+
+import "strings"
+
+const (
+	BoardWidth  = 10
+	BoardHeight = 20
+)
+
+// Cell is the content of a single board square: empty, garbage or a
+// cell of a locked piece that remembers the piece's type.
+type Cell int
+
+const (
+	Empty Cell = iota
+	// Garbage is a cell of a garbage row, which was never part of a piece.
+	Garbage
+	// pieceCells is the cell of I; the other types follow in order.
+	pieceCells
+)
+
+// PieceCell returns the cell a locked piece of type t leaves behind.
+func PieceCell(t TetrominoType) Cell {
+	return pieceCells + Cell(t)
+}
+
+// Piece returns the type of the piece a cell was locked from. Empty and
+// garbage cells have none.
+func (c Cell) Piece() (TetrominoType, bool) {
+	if c < pieceCells {
+		return 0, false
+	}
+	return TetrominoType(c - pieceCells), true
+}
+
+// Board is the playfield, indexed as Board[y][x] with y growing downwards.
+type Board [][]Cell
+
+func NewBoard() Board {
+	board := make(Board, BoardHeight)
+	for i := range board {
+		board[i] = make([]Cell, BoardWidth)
+	}
+	return board
+}
+
+// Collides reports whether t overlaps a wall, the floor or a locked cell.
+// Cells above the top of the board are only checked against the walls.
+func (b Board) Collides(t *Tetromino) bool {
+	for y, row := range t.Shape() {
+		for x, cell := range row {
+			if cell == 0 {
+				continue
+			}
+			boardX := t.X + x
+			boardY := t.Y + y
+			if boardX < 0 || boardX >= BoardWidth || boardY >= BoardHeight {
+				return true
+			}
+			if boardY >= 0 && b[boardY][boardX] != Empty {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Drop returns t moved down as far as it fits.
+func (b Board) Drop(t Tetromino) Tetromino {
+	for {
+		t.Y++
+		if b.Collides(&t) {
+			t.Y--
+			return t
+		}
+	}
+}
+
+// Lock writes t into the board as cells of its type. Cells above the top
+// are dropped.
+func (b Board) Lock(t *Tetromino) {
+	for y, row := range t.Shape() {
+		for x, cell := range row {
+			if cell == 0 {
+				continue
+			}
+			boardY := t.Y + y
+			if boardY >= 0 {
+				b[boardY][t.X+x] = PieceCell(t.Type)
+			}
+		}
+	}
+}
+
+// ClearLines removes every full row, shifts the rows above it down and
+// returns how many rows were removed.
+func (b Board) ClearLines() int {
+	cleared, _ := b.clearLines()
+	return cleared
+}
+
+// clearLines is ClearLines that also counts the garbage rows removed.
+func (b Board) clearLines() (cleared, garbage int) {
+	for y := BoardHeight - 1; y >= 0; y-- {
+		if !b.rowFull(y) {
+			continue
+		}
+		if b.hasGarbage(y) {
+			garbage++
+		}
+		for i := y; i > 0; i-- {
+			b[i] = b[i-1]
+		}
+		b[0] = make([]Cell, BoardWidth)
+		cleared++
+		y++
+	}
+	return cleared, garbage
+}
+
+// AddGarbage pushes a garbage row with a hole at column hole in from the
+// bottom, moving every row up. It reports false if a filled row was
+// pushed off the top.
+func (b Board) AddGarbage(hole int) bool {
+	fits := true
+	for _, cell := range b[0] {
+		if cell != Empty {
+			fits = false
+		}
+	}
+	copy(b, b[1:])
+	row := make([]Cell, BoardWidth)
+	for x := range row {
+		if x != hole {
+			row[x] = Garbage
+		}
+	}
+	b[BoardHeight-1] = row
+	return fits
+}
+
+// occupied reports whether the cell at (x, y) is a wall, the floor or a
+// locked block. Cells above the board are open.
+func (b Board) occupied(x, y int) bool {
+	if x < 0 || x >= BoardWidth || y >= BoardHeight {
+		return true
+	}
+	return y >= 0 && b[y][x] != Empty
+}
+
+// IsEmpty reports whether no cell is filled.
+func (b Board) IsEmpty() bool {
+	for _, row := range b {
+		for _, cell := range row {
+			if cell != Empty {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (b Board) rowFull(y int) bool {
+	for _, cell := range b[y] {
+		if cell == Empty {
+			return false
+		}
+	}
+	return true
+}
+
+func (b Board) hasGarbage(y int) bool {
+	for _, cell := range b[y] {
+		if cell == Garbage {
+			return true
+		}
+	}
+	return false
+}
+
+// Copy returns a deep copy of the board.
+func (b Board) Copy() Board {
+	board := make(Board, len(b))
+	for i := range b {
+		board[i] = append([]Cell(nil), b[i]...)
+	}
+	return board
+}
+
+// EncodeBoard writes each row of b as a string with a character per
+// cell: '.' for empty, 'G' for garbage or the letter of a piece.
+func EncodeBoard(b Board) []string {
+	rows := make([]string, len(b))
+	for y, row := range b {
+		var s strings.Builder
+		for _, cell := range row {
+			switch t, ok := cell.Piece(); {
+			case ok:
+				s.WriteString(t.String())
+			case cell == Garbage:
+				s.WriteByte('G')
+			default:
+				s.WriteByte('.')
+			}
+		}
+		rows[y] = s.String()
+	}
+	return rows
+}
+
+// DecodeBoard reads a board written by EncodeBoard. Unknown characters
+// and missing cells are empty.
+func DecodeBoard(rows []string) Board {
+	b := NewBoard()
+	for y, row := range rows {
+		if y >= len(b) {
+			break
+		}
+		for x := 0; x < len(row) && x < len(b[y]); x++ {
+			if row[x] == 'G' {
+				b[y][x] = Garbage
+			} else if t, err := ParseTetrominoType(row[x : x+1]); err == nil {
+				b[y][x] = PieceCell(t)
+			}
+		}
+	}
+	return b
+}
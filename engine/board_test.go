@@ -0,0 +1,25 @@
+package engine
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestBoardText(t *testing.T) {
+	b := testBoard("####.#####")
+	b[BoardHeight-2][2] = PieceCell(T)
+	b[BoardHeight-1][0] = PieceCell(I)
+	rows := EncodeBoard(b)
+	if got, want := rows[BoardHeight-2], "..T......."; got != want {
+		t.Errorf("row %q, want %q", got, want)
+	}
+	if got, want := rows[BoardHeight-1], "IGGG.GGGGG"; got != want {
+		t.Errorf("row %q, want %q", got, want)
+	}
+	if got := DecodeBoard(rows); !slices.EqualFunc(got, b, slices.Equal) {
+		t.Errorf("decoded %q, want %q", EncodeBoard(got), rows)
+	}
+	if got := DecodeBoard([]string{"x?"}); !got.IsEmpty() {
+		t.Errorf("unknown cells decoded as %q, want empty", EncodeBoard(got))
+	}
+}
@@ -0,0 +1,468 @@
+// Package engine implements the rules of tetris without any rendering or
+// input dependency. Frontends report the buttons held down as an Input
+// value, feed it to Tick once per frame and draw from the exported state.
+package engine
+
+import (
+	"math/rand"
+	"time"
+)
+
+// TicksPerSecond is the rate at which frontends are expected to call Tick.
+const TicksPerSecond = 60
+
+// Input is the set of buttons held down on one tick.
+type Input uint8
+
+const (
+	InputLeft Input = 1 << iota
+	InputRight
+	InputSoftDrop
+	InputRotateRight
+	InputRotateLeft
+	InputHold
+	InputHardDrop
+)
+
+// Points awarded per row a piece is dropped by the player.
+const (
+	softDropPoints = 1
+	hardDropPoints = 2
+)
+
+type Game struct {
+	Board            Board
+	CurrentTetromino *Tetromino
+	holdPiece        TetrominoType
+	hasHold          bool
+	canHold          bool
+	gameOver         bool
+	completed        bool
+	score            int
+	lines            int
+	pieces           int
+	splits           []int
+	level            int
+	combo            int
+	backToBack       bool
+	lastRotated      bool
+	lastKick         int
+	events           []Event
+	frame            int
+	gravityTimer     int // in gravityUnit steps
+	lockTimer        int
+	lockResets       int
+	lowestY          int
+	seed             int64
+	rand             *rand.Rand
+	garbage          garbage
+	incoming         []int // garbage received, in attacks not risen yet
+	rules            Rules
+	randomizer       Randomizer
+	queue            []TetrominoType
+	handling         Handling
+	controller       controller
+}
+
+// NewSeed returns a seed for games that were not given one.
+func NewSeed() int64 {
+	return time.Now().UnixNano()
+}
+
+// NewGame starts a game whose randomness all comes from seed, so two games
+// with the same seed and rules deal the same pieces.
+func NewGame(seed int64, rules Rules) *Game {
+	return NewGameWithRandomizer(seed, rules, nil)
+}
+
+// NewGameWithRandomizer starts a game whose pieces come from r instead of
+// the randomizer named in rules, for callers that bring their own piece
+// generator. A nil r uses the rules' randomizer.
+func NewGameWithRandomizer(seed int64, rules Rules, r Randomizer) *Game {
+	game := &Game{
+		Board:    NewBoard(),
+		seed:     seed,
+		rand:     rand.New(rand.NewSource(seed)),
+		rules:    rules,
+		canHold:  true,
+		level:    1,
+		combo:    -1,
+		handling: DefaultHandling(),
+	}
+	if r == nil {
+		r = rules.newRandomizer(game.rand)
+	}
+	game.randomizer = r
+	game.garbage = newGarbage(seed, rules)
+	game.garbage.fill(game.Board)
+	game.spawn()
+	return game
+}
+
+// spawn takes the next piece from the queue and refills it from the
+// randomizer, keeping Rules.NextCount pieces visible.
+func (game *Game) spawn() {
+	for len(game.queue) <= game.rules.NextCount {
+		game.queue = append(game.queue, game.randomizer.Next())
+	}
+	next := game.queue[0]
+	game.queue = append(game.queue[:0], game.queue[1:]...)
+	game.startPiece(NewTetromino(next))
+}
+
+// startPiece makes t the active piece and resets its gravity and lock
+// state. The game is over if t does not fit.
+func (game *Game) startPiece(t *Tetromino) {
+	game.CurrentTetromino = t
+	game.gravityTimer = 0
+	game.lockTimer = 0
+	game.lockResets = 0
+	game.lowestY = t.Y
+	game.lastRotated = false
+	if game.IsCollision() {
+		game.gameOver = true
+	}
+}
+
+// Hold swaps the active piece with the one in the hold slot, or with the
+// next piece if the slot is empty. The piece comes back in its spawn
+// orientation. Hold can be used once per piece; locking re-enables it.
+func (game *Game) Hold() bool {
+	if game.gameOver || !game.canHold {
+		return false
+	}
+	current := game.CurrentTetromino.Type
+	if game.hasHold {
+		game.startPiece(NewTetromino(game.holdPiece))
+	} else {
+		game.spawn()
+	}
+	game.holdPiece = current
+	game.hasHold = true
+	game.canHold = false
+	return true
+}
+
+func (game *Game) MoveLeft() bool {
+	return game.move(-1, 0)
+}
+
+func (game *Game) MoveRight() bool {
+	return game.move(1, 0)
+}
+
+// SoftDrop moves the piece one row down without locking it and scores a
+// point for the row.
+func (game *Game) SoftDrop() bool {
+	if !game.move(0, 1) {
+		return false
+	}
+	game.score += softDropPoints
+	return true
+}
+
+// HardDrop drops the piece straight to where it lands and locks it,
+// scoring two points per row fallen.
+func (game *Game) HardDrop() {
+	if game.gameOver {
+		return
+	}
+	for game.move(0, 1) {
+		game.score += hardDropPoints
+	}
+	game.lock()
+}
+
+func (game *Game) move(dx, dy int) bool {
+	if game.gameOver {
+		return false
+	}
+	game.CurrentTetromino.X += dx
+	game.CurrentTetromino.Y += dy
+	if game.IsCollision() {
+		game.CurrentTetromino.X -= dx
+		game.CurrentTetromino.Y -= dy
+		return false
+	}
+	game.lastRotated = false
+	game.moved()
+	return true
+}
+
+// moved is called after the piece moves or rotates. On the ground this
+// restarts the lock delay, at most Rules.LockResets times per piece;
+// reaching a new lowest row restarts it and earns the resets back.
+func (game *Game) moved() {
+	if y := game.CurrentTetromino.Y; y > game.lowestY {
+		game.lowestY = y
+		game.lockResets = 0
+		game.lockTimer = 0
+		return
+	}
+	if game.lockTimer > 0 && game.lockResets < game.rules.LockResets {
+		game.lockTimer = 0
+		game.lockResets++
+	}
+}
+
+// RotateRight turns the piece clockwise, kicking it off walls, the floor
+// and locked cells as described by the Super Rotation System.
+func (game *Game) RotateRight() bool {
+	return game.rotate((game.CurrentTetromino.Rotation + 1) % 4)
+}
+
+// RotateLeft turns the piece counter-clockwise with SRS wall kicks.
+func (game *Game) RotateLeft() bool {
+	return game.rotate((game.CurrentTetromino.Rotation + 3) % 4)
+}
+
+func (game *Game) rotate(to Rotation) bool {
+	if game.gameOver {
+		return false
+	}
+	kick := game.Board.rotate(game.CurrentTetromino, to)
+	if kick < 0 {
+		return false
+	}
+	game.lastRotated = true
+	game.lastKick = kick
+	game.moved()
+	return true
+}
+
+// Tick advances the game by one frame with the buttons in held down:
+// input is applied first, then gravity, then the lock delay of a piece
+// resting on the ground. A game with a time limit ends on its last tick.
+func (game *Game) Tick(held Input) {
+	if game.gameOver {
+		return
+	}
+	game.frame++
+	game.handleInput(held)
+	if game.gameOver {
+		return
+	}
+	game.fall(held&InputSoftDrop != 0)
+	game.updateLock()
+	if limit := game.rules.TimeLimit; limit > 0 && game.frame >= limit && !game.gameOver {
+		game.complete()
+	}
+}
+
+// fall applies gravity, sped up by the soft drop factor while soft drop is
+// held. Rows fallen by soft drop score points.
+func (game *Game) fall(soft bool) {
+	gravity := game.rules.gravity(game.level)
+	if soft {
+		gravity *= game.handling.SDF
+	}
+	game.gravityTimer += min(gravity, MaxG*gravityUnit)
+	for game.gravityTimer >= gravityUnit {
+		game.gravityTimer -= gravityUnit
+		if !game.move(0, 1) {
+			game.gravityTimer = 0
+			return
+		}
+		if soft {
+			game.score += softDropPoints
+		}
+	}
+}
+
+// updateLock locks the piece once it has rested on the ground for
+// Rules.LockDelay ticks. The timer only pauses while the piece is in the
+// air, so kicking it up off the floor does not start the delay over.
+func (game *Game) updateLock() {
+	if !game.grounded() {
+		return
+	}
+	game.lockTimer++
+	if game.lockTimer >= game.rules.LockDelay {
+		game.lock()
+	}
+}
+
+// grounded reports whether the piece cannot fall any further.
+func (game *Game) grounded() bool {
+	below := *game.CurrentTetromino
+	below.Y++
+	return game.Board.Collides(&below)
+}
+
+// lock fixes the current piece in the board, clears full rows and spawns
+// the next piece. Garbage received from an opponent rises when a piece
+// locks without clearing a row.
+func (game *Game) lock() {
+	spin := game.tSpin()
+	game.Board.Lock(game.CurrentTetromino)
+	cleared, garbage := game.Board.clearLines()
+	game.pieces++
+	game.lines += cleared
+	game.garbage.left -= garbage
+	event := game.scoreClear(cleared, spin)
+	event.Attack = game.cancelGarbage(event.attackLines())
+	game.emit(event)
+	game.split()
+	if game.goalReached() {
+		game.complete()
+		return
+	}
+	game.levelUp()
+	if !game.garbage.fill(game.Board) || cleared == 0 && !game.riseGarbage() {
+		game.gameOver = true
+		return
+	}
+	game.canHold = true
+	game.spawn()
+}
+
+// goalReached reports whether enough lines or levels have been cleared
+// to complete the game.
+func (game *Game) goalReached() bool {
+	rules := game.rules
+	return rules.LineGoal > 0 && game.lines >= rules.LineGoal ||
+		rules.LevelGoal > 0 && game.lines >= rules.LevelGoal*LinesPerLevel ||
+		rules.GarbageLines > 0 && game.garbage.left <= 0
+}
+
+// complete ends the game by reaching its goal.
+func (game *Game) complete() {
+	game.completed = true
+	game.gameOver = true
+}
+
+// SplitLines is how many lines apart split times are taken.
+const SplitLines = 10
+
+// split records the tick on which each multiple of SplitLines was
+// reached.
+func (game *Game) split() {
+	for len(game.splits) < game.lines/SplitLines {
+		game.splits = append(game.splits, game.frame)
+	}
+}
+
+// levelUp raises the level by one for every LinesPerLevel rows cleared.
+func (game *Game) levelUp() {
+	level := 1 + game.lines/LinesPerLevel
+	if level <= game.level {
+		return
+	}
+	game.level = level
+	game.emit(Event{Kind: EventLevelUp, Frame: game.frame, Level: level})
+}
+
+func (game *Game) IsCollision() bool {
+	return game.Board.Collides(game.CurrentTetromino)
+}
+
+// Ghost returns the current piece moved down to where it would land.
+func (game *Game) Ghost() Tetromino {
+	return game.Board.Drop(*game.CurrentTetromino)
+}
+
+// Next returns the upcoming pieces, soonest first.
+func (game *Game) Next() []TetrominoType {
+	return game.queue[:game.rules.NextCount]
+}
+
+// HoldPiece returns the piece in the hold slot, if there is one.
+func (game *Game) HoldPiece() (TetrominoType, bool) {
+	return game.holdPiece, game.hasHold
+}
+
+// CanHold reports whether Hold may be used on the current piece.
+func (game *Game) CanHold() bool {
+	return game.canHold
+}
+
+// IsGameOver reports whether the game has ended, by topping out or by
+// completing its goal.
+func (game *Game) IsGameOver() bool {
+	return game.gameOver
+}
+
+// Completed reports whether the game ended by reaching its goal.
+func (game *Game) Completed() bool {
+	return game.completed
+}
+
+// SetHandling changes how held buttons repeat. Call it before the first
+// Tick so recordings capture it.
+func (game *Game) SetHandling(h Handling) {
+	game.handling = h
+}
+
+func (game *Game) Handling() Handling {
+	return game.handling
+}
+
+// Frame returns the number of ticks played so far.
+func (game *Game) Frame() int {
+	return game.frame
+}
+
+// Elapsed returns the game time played so far, counted in ticks so it
+// does not depend on how fast the frontend ran.
+func (game *Game) Elapsed() time.Duration {
+	return ticksDuration(game.frame)
+}
+
+func ticksDuration(ticks int) time.Duration {
+	return time.Duration(ticks) * time.Second / TicksPerSecond
+}
+
+func (game *Game) Seed() int64 {
+	return game.seed
+}
+
+func (game *Game) Rules() Rules {
+	return game.rules
+}
+
+func (game *Game) Score() int {
+	return game.score
+}
+
+func (game *Game) Level() int {
+	return game.level
+}
+
+func (game *Game) Lines() int {
+	return game.lines
+}
+
+// TimeLeft returns the game time left under Rules.TimeLimit, or zero
+// without a limit.
+func (game *Game) TimeLeft() time.Duration {
+	return ticksDuration(max(0, game.rules.TimeLimit-game.frame))
+}
+
+// GarbageLeft returns how many garbage rows are left to clear, including
+// those not yet on the board.
+func (game *Game) GarbageLeft() int {
+	return max(0, game.garbage.left)
+}
+
+// Pieces returns how many pieces have locked.
+func (game *Game) Pieces() int {
+	return game.pieces
+}
+
+// PiecesPerSecond returns the pieces locked per second of game time.
+func (game *Game) PiecesPerSecond() float64 {
+	if game.frame == 0 {
+		return 0
+	}
+	return float64(game.pieces) * TicksPerSecond / float64(game.frame)
+}
+
+// Splits returns the game time at which every SplitLines lines were
+// reached.
+func (game *Game) Splits() []time.Duration {
+	splits := make([]time.Duration, len(game.splits))
+	for i, frame := range game.splits {
+		splits[i] = ticksDuration(frame)
+	}
+	return splits
+}
@@ -0,0 +1,103 @@
+package engine
+
+import "testing"
+
+// testRules deals pieces in the given order under slow fixed gravity.
+func testRules(pieces ...TetrominoType) Rules {
+	rules := DefaultRules()
+	rules.Randomizer = RandomizerSequence
+	rules.Sequence = pieces
+	rules.Gravity = GravityFixed
+	rules.FixedG = 1.0 / 60
+	return rules
+}
+
+// testBoard builds a board from rows drawn with '#' for filled and '.' for
+// empty cells. The rows are the bottom of the board; those above are empty.
+func testBoard(rows ...string) Board {
+	board := NewBoard()
+	top := BoardHeight - len(rows)
+	for i, row := range rows {
+		for x, c := range row {
+			if c == '#' {
+				board[top+i][x] = Garbage
+			}
+		}
+	}
+	return board
+}
+
+// landed returns a game whose first piece, a T, rests on the floor.
+func landed() *Game {
+	game := NewGame(1, testRules(T))
+	ghost := game.Ghost()
+	game.CurrentTetromino = &ghost
+	game.lowestY = ghost.Y
+	return game
+}
+
+// lockTick ticks game with the buttons input returns for each tick,
+// counting from 1, and returns the tick on which the first piece locked.
+func lockTick(game *Game, input func(tick int) Input) int {
+	for tick := 1; tick <= 6000; tick++ {
+		game.Tick(input(tick))
+		if game.Pieces() > 0 {
+			return tick
+		}
+	}
+	return -1
+}
+
+func TestLockDelay(t *testing.T) {
+	tests := []struct {
+		name  string
+		input func(tick int) Input
+		want  int
+	}{
+		{"idle", func(int) Input { return 0 }, DefaultLockDelay},
+		{"hard drop", func(tick int) Input {
+			if tick == 5 {
+				return InputHardDrop
+			}
+			return 0
+		}, 5},
+		{"one shift", func(tick int) Input {
+			if tick == 10 {
+				return InputLeft
+			}
+			return 0
+		}, 10 + DefaultLockDelay - 1},
+		{"shift until the resets run out", func(tick int) Input {
+			switch {
+			case tick%4 == 2:
+				return InputLeft
+			case tick%4 == 0:
+				return InputRight
+			}
+			return 0
+		}, 2*DefaultLockResets + DefaultLockDelay - 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lockTick(landed(), tt.input); got != tt.want {
+				t.Errorf("locked on tick %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// A piece that keeps kicking up off the floor must still lock once its
+// resets are spent, instead of restarting the delay forever.
+func TestLockResetCap(t *testing.T) {
+	buttons := []Input{InputRotateRight, InputRotateLeft}
+	got := lockTick(landed(), func(tick int) Input {
+		if tick%4 == 0 {
+			return buttons[tick/4%2]
+		}
+		return 0
+	})
+	limit := (DefaultLockResets + 1) * DefaultLockDelay
+	if got < 0 || got > limit {
+		t.Errorf("locked on tick %d while rotating on the floor, want by %d", got, limit)
+	}
+}
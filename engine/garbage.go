@@ -0,0 +1,68 @@
+package engine
+
+import "math/rand"
+
+// DefaultMessiness moves the hole of one garbage row in four.
+const DefaultMessiness = 0.25
+
+// garbageSeed is mixed into the game seed so garbage holes come from their
+// own source and the piece sequence of a seed is the same in every mode.
+const garbageSeed = 0x6761726261676521
+
+// garbage deals the rows of a dig game and of attacks from an opponent.
+type garbage struct {
+	rand      *rand.Rand
+	messiness float64
+	height    int
+	// left counts the rows not cleared yet, queued the rows not on the
+	// board yet.
+	left   int
+	queued int
+	hole   int
+}
+
+func newGarbage(seed int64, rules Rules) garbage {
+	g := garbage{
+		messiness: rules.Messiness,
+		height:    rules.GarbageHeight,
+		left:      rules.GarbageLines,
+		queued:    rules.GarbageLines,
+	}
+	g.rand = rand.New(rand.NewSource(seed ^ garbageSeed))
+	g.hole = g.rand.Intn(BoardWidth)
+	return g
+}
+
+// fill tops the board up to height garbage rows while some are queued.
+// It reports false if a row pushed the stack out of the top.
+func (g *garbage) fill(board Board) bool {
+	for g.queued > 0 && g.left-g.queued < g.height {
+		if !board.AddGarbage(g.nextHole()) {
+			return false
+		}
+		g.queued--
+	}
+	return true
+}
+
+// nextHole returns the hole column of the next row, moving it to another
+// column with probability messiness.
+func (g *garbage) nextHole() int {
+	if g.rand.Float64() < g.messiness {
+		g.hole = (g.hole + 1 + g.rand.Intn(BoardWidth-1)) % BoardWidth
+	}
+	return g.hole
+}
+
+// rise pushes lines garbage rows with a hole in a new random column up
+// from the bottom, as one attack. It reports false if the stack was
+// pushed out of the top.
+func (g *garbage) rise(board Board, lines int) bool {
+	hole := g.rand.Intn(BoardWidth)
+	for range lines {
+		if !board.AddGarbage(hole) {
+			return false
+		}
+	}
+	return true
+}
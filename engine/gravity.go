@@ -0,0 +1,71 @@
+package engine
+
+import "math"
+
+// Names of the gravity curves accepted in Rules.Gravity.
+const (
+	GravityFixed     = "fixed"
+	GravityGuideline = "guideline"
+	GravityNES       = "nes"
+)
+
+// LinesPerLevel is how many cleared rows advance the level by one.
+const LinesPerLevel = 10
+
+// Gravity is measured in G, rows fallen per tick. The engine accumulates
+// it in fixed point, gravityUnit steps to a row, so fractional speeds
+// play out the same on every machine.
+const gravityUnit = 1 << 16
+
+// MaxG is 20G: enough to drop a piece from the top of the board to the
+// floor on the tick it appears.
+const MaxG = BoardHeight
+
+// twentyGLevel is the first level at which the guideline curve plays at
+// MaxG.
+const twentyGLevel = 20
+
+// nesFrames is the NES frames-per-row table, indexed by level-1. Levels
+// past the end of the table play at MaxG.
+var nesFrames = [...]int{
+	48, 43, 38, 33, 28, 23, 18, 13, 8, 6,
+	5, 5, 5, 4, 4, 4, 3, 3, 3, 2,
+	2, 2, 2, 2, 2, 2, 2, 2, 2, 1,
+}
+
+// guidelineG applies the guideline formula: a row every
+// (0.8-(level-1)*0.007)^(level-1) seconds.
+func guidelineG(level int) float64 {
+	if level >= twentyGLevel {
+		return MaxG
+	}
+	seconds := math.Pow(0.8-float64(level-1)*0.007, float64(level-1))
+	return min(MaxG, 1/(seconds*TicksPerSecond))
+}
+
+func nesG(level int) float64 {
+	if level > len(nesFrames) {
+		return MaxG
+	}
+	return 1 / float64(nesFrames[level-1])
+}
+
+// GravityG returns the gravity the rules give at level, in G.
+func (rules Rules) GravityG(level int) float64 {
+	switch rules.Gravity {
+	case GravityGuideline:
+		return guidelineG(level)
+	case GravityNES:
+		return nesG(level)
+	}
+	if rules.FixedG > 0 {
+		return rules.FixedG
+	}
+	return DefaultG
+}
+
+// gravity returns the gravity at level in gravityUnit steps per tick,
+// rounded up so a row every n ticks really takes n ticks.
+func (rules Rules) gravity(level int) int {
+	return int(math.Ceil(rules.GravityG(level) * gravityUnit))
+}
@@ -0,0 +1,126 @@
+package engine
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Handling is a player's tuning of how held buttons repeat. DAS and ARR
+// are in ticks.
+type Handling struct {
+	// DAS (delayed auto shift) is how long left or right must be held
+	// before the piece starts to repeat.
+	DAS int `json:"das"`
+	// ARR (auto repeat rate) is the time between repeated shifts once DAS
+	// has charged. Zero shifts straight to the wall.
+	ARR int `json:"arr"`
+	// SDF (soft drop factor) is how many times faster than gravity the
+	// piece falls while soft drop is held.
+	SDF int `json:"sdf"`
+}
+
+const (
+	DefaultDAS = 10
+	DefaultARR = 2
+	DefaultSDF = 20
+)
+
+func DefaultHandling() Handling {
+	return Handling{DAS: DefaultDAS, ARR: DefaultARR, SDF: DefaultSDF}
+}
+
+func (h Handling) Validate() error {
+	if h.DAS < 0 || h.ARR < 0 {
+		return fmt.Errorf("engine: negative DAS %d or ARR %d", h.DAS, h.ARR)
+	}
+	if h.SDF < 1 {
+		return fmt.Errorf("engine: soft drop factor %d below 1", h.SDF)
+	}
+	return nil
+}
+
+// ParseTicks reads a duration given either as a number of ticks, "10",
+// or as a time, "167ms", which is rounded to the nearest tick.
+func ParseTicks(s string) (int, error) {
+	if n, err := strconv.Atoi(s); err == nil {
+		return n, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("engine: %q is neither ticks nor a duration", s)
+	}
+	tick := time.Second / TicksPerSecond
+	return int((d + tick/2) / tick), nil
+}
+
+// controller tracks held buttons between ticks to find new presses and
+// to time auto shift.
+type controller struct {
+	held     Input
+	shift    int
+	dasTimer int
+}
+
+// handleInput applies the buttons held on this tick. Rotation, hold and
+// hard drop act once per press; left and right shift on press and then
+// repeat per the game's Handling. Soft drop is applied with gravity.
+func (game *Game) handleInput(held Input) {
+	pressed := held &^ game.controller.held
+	game.controller.held = held
+
+	if pressed&InputHold != 0 {
+		game.Hold()
+	}
+	if pressed&InputRotateRight != 0 {
+		game.RotateRight()
+	}
+	if pressed&InputRotateLeft != 0 {
+		game.RotateLeft()
+	}
+	game.autoShift(held, pressed)
+	if pressed&InputHardDrop != 0 {
+		game.HardDrop()
+	}
+}
+
+func (game *Game) autoShift(held, pressed Input) {
+	c := &game.controller
+	dir := 0
+	switch {
+	case pressed&InputLeft != 0:
+		dir = -1
+	case pressed&InputRight != 0:
+		dir = 1
+	case c.shift == -1 && held&InputLeft != 0:
+		dir = -1
+	case c.shift == 1 && held&InputRight != 0:
+		dir = 1
+	case held&InputLeft != 0:
+		dir = -1
+	case held&InputRight != 0:
+		dir = 1
+	}
+
+	if dir == 0 {
+		c.shift = 0
+		return
+	}
+	if dir != c.shift || pressed&(InputLeft|InputRight) != 0 {
+		c.shift = dir
+		c.dasTimer = 0
+		game.move(dir, 0)
+		return
+	}
+
+	h := game.handling
+	c.dasTimer++
+	switch {
+	case c.dasTimer < h.DAS:
+	case h.ARR == 0:
+		for game.move(dir, 0) {
+		}
+	case (c.dasTimer-h.DAS)%h.ARR == 0:
+		game.move(dir, 0)
+	}
+}
@@ -0,0 +1,66 @@
+package engine
+
+import "testing"
+
+func TestAutoShift(t *testing.T) {
+	tests := []struct {
+		name     string
+		handling Handling
+		// want is the piece's column after each tick of holding right,
+		// starting from spawn at column 3.
+		want []int
+	}{
+		{"default", Handling{DAS: 10, ARR: 2, SDF: 1},
+			[]int{4, 4, 4, 4, 4, 4, 4, 4, 4, 4, 5, 5, 6, 6, 7, 7}},
+		{"instant repeat", Handling{DAS: 10, ARR: 0, SDF: 1},
+			[]int{4, 4, 4, 4, 4, 4, 4, 4, 4, 4, 7, 7}},
+		{"no delay", Handling{DAS: 0, ARR: 1, SDF: 1},
+			[]int{4, 5, 6, 7, 7}},
+		{"slow", Handling{DAS: 3, ARR: 3, SDF: 1},
+			[]int{4, 4, 4, 5, 5, 5, 6, 6, 6, 7}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := NewGame(1, testRules(T))
+			game.SetHandling(tt.handling)
+			for tick, want := range tt.want {
+				game.Tick(InputRight)
+				if got := game.CurrentTetromino.X; got != want {
+					t.Fatalf("tick %d: column %d, want %d", tick+1, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestShiftOnEveryPress(t *testing.T) {
+	game := NewGame(1, testRules(T))
+	for _, held := range []Input{InputLeft, 0, InputLeft, 0, InputLeft} {
+		game.Tick(held)
+	}
+	if got := game.CurrentTetromino.X; got != 0 {
+		t.Errorf("column %d after three taps, want 0", got)
+	}
+}
+
+func TestParseTicks(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int
+		wantErr bool
+	}{
+		{"10", 10, false},
+		{"0", 0, false},
+		{"167ms", 10, false},
+		{"1s", 60, false},
+		{"8ms", 0, false},
+		{"9ms", 1, false},
+		{"fast", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseTicks(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseTicks(%q) = %d, %v; want %d", tt.in, got, err, tt.want)
+		}
+	}
+}
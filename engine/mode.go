@@ -0,0 +1,67 @@
+package engine
+
+import "time"
+
+// Names of the built-in modes.
+const (
+	ModeEndless  = "endless"
+	ModeSprint   = "sprint"
+	ModeUltra    = "ultra"
+	ModeMarathon = "marathon"
+	ModeDig      = "dig"
+)
+
+// Mode is a way to play: when a game ends and how finished games are
+// ranked against each other.
+type Mode struct {
+	Name        string
+	Description string
+	// LineGoal completes the game once this many lines are cleared.
+	LineGoal int
+	// LevelGoal completes the game once this level is cleared.
+	LevelGoal int
+	// TimeLimit completes the game once this much game time has passed.
+	TimeLimit time.Duration
+	// GarbageLines completes the game once this many garbage rows are
+	// cleared, GarbageHeight of them on the board at a time.
+	GarbageLines  int
+	GarbageHeight int
+	// RankByTime ranks completed games by how fast they finished instead
+	// of by score.
+	RankByTime bool
+}
+
+var modes = []Mode{
+	{Name: ModeEndless, Description: "Play until the board tops out"},
+	{Name: ModeSprint, Description: "Clear 40 lines as fast as possible", LineGoal: 40, RankByTime: true},
+	{Name: ModeUltra, Description: "Score as much as possible in 2 minutes", TimeLimit: 2 * time.Minute},
+	{Name: ModeMarathon, Description: "Clear 15 levels, 150 lines", LineGoal: 150, LevelGoal: 15},
+	{Name: ModeDig, Description: "Dig through 18 rows of garbage", GarbageLines: 18, GarbageHeight: 10, RankByTime: true},
+}
+
+// Modes returns the built-in modes in menu order.
+func Modes() []Mode {
+	return append([]Mode(nil), modes...)
+}
+
+// LookupMode returns the built-in mode called name. Unknown names are
+// played as endless.
+func LookupMode(name string) (Mode, bool) {
+	for _, m := range modes {
+		if m.Name == name {
+			return m, true
+		}
+	}
+	return Mode{Name: name}, false
+}
+
+// Rules returns base set up for the mode.
+func (m Mode) Rules(base Rules) Rules {
+	base.Mode = m.Name
+	base.LineGoal = m.LineGoal
+	base.LevelGoal = m.LevelGoal
+	base.TimeLimit = int(m.TimeLimit * TicksPerSecond / time.Second)
+	base.GarbageLines = m.GarbageLines
+	base.GarbageHeight = m.GarbageHeight
+	return base
+}
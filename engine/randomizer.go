@@ -0,0 +1,131 @@
+package engine
+
+import "math/rand"
+
+// Randomizer decides which tetromino comes next.
+type Randomizer interface {
+	Next() TetrominoType
+}
+
+// Randomizer names accepted in Rules.
+const (
+	RandomizerBag      = "bag"
+	RandomizerRandom   = "random"
+	RandomizerHistory  = "history"
+	RandomizerSequence = "sequence"
+)
+
+var allTetrominoes = []TetrominoType{I, J, L, O, S, T, Z}
+
+type bagRandomizer struct {
+	rand *rand.Rand
+	bag  []TetrominoType
+}
+
+// NewBagRandomizer deals all seven pieces in a shuffled order before
+// refilling, so no piece is ever more than 12 pieces away.
+func NewBagRandomizer(r *rand.Rand) Randomizer {
+	return &bagRandomizer{rand: r}
+}
+
+func (b *bagRandomizer) Next() TetrominoType {
+	if len(b.bag) == 0 {
+		b.bag = append(b.bag, allTetrominoes...)
+		b.rand.Shuffle(len(b.bag), func(i, j int) {
+			b.bag[i], b.bag[j] = b.bag[j], b.bag[i]
+		})
+	}
+	t := b.bag[0]
+	b.bag = b.bag[1:]
+	return t
+}
+
+type pureRandomizer struct {
+	rand *rand.Rand
+}
+
+// NewPureRandomizer picks every piece independently and uniformly.
+func NewPureRandomizer(r *rand.Rand) Randomizer {
+	return &pureRandomizer{rand: r}
+}
+
+func (p *pureRandomizer) Next() TetrominoType {
+	return allTetrominoes[p.rand.Intn(len(allTetrominoes))]
+}
+
+const historyRolls = 6
+
+type historyRandomizer struct {
+	rand    *rand.Rand
+	history [4]TetrominoType
+	first   bool
+}
+
+// NewHistoryRandomizer is the TGM randomizer: it remembers the last four
+// pieces and rerolls up to six times when the pick is among them. The
+// first piece is never S, Z or O.
+func NewHistoryRandomizer(r *rand.Rand) Randomizer {
+	return &historyRandomizer{
+		rand:    r,
+		history: [4]TetrominoType{Z, S, S, Z},
+		first:   true,
+	}
+}
+
+func (h *historyRandomizer) Next() TetrominoType {
+	var t TetrominoType
+	if h.first {
+		h.first = false
+		starts := []TetrominoType{I, J, L, T}
+		t = starts[h.rand.Intn(len(starts))]
+	} else {
+		for roll := 0; roll < historyRolls; roll++ {
+			t = allTetrominoes[h.rand.Intn(len(allTetrominoes))]
+			if !h.seen(t) {
+				break
+			}
+		}
+	}
+	copy(h.history[:], h.history[1:])
+	h.history[len(h.history)-1] = t
+	return t
+}
+
+func (h *historyRandomizer) seen(t TetrominoType) bool {
+	for _, p := range h.history {
+		if p == t {
+			return true
+		}
+	}
+	return false
+}
+
+type sequenceRandomizer struct {
+	pieces []TetrominoType
+	next   int
+}
+
+// NewSequenceRandomizer deals the given pieces in order, starting over
+// when they run out.
+func NewSequenceRandomizer(pieces ...TetrominoType) Randomizer {
+	return &sequenceRandomizer{pieces: pieces}
+}
+
+func (s *sequenceRandomizer) Next() TetrominoType {
+	t := s.pieces[s.next]
+	s.next = (s.next + 1) % len(s.pieces)
+	return t
+}
+
+// ParseSequence reads a piece sequence written as letters, e.g. "IJLOSTZ".
+func ParseSequence(s string) ([]TetrominoType, error) {
+	pieces := make([]TetrominoType, 0, len(s))
+	for _, r := range s {
+		t, err := ParseTetrominoType(string(r))
+		if err != nil {
+			return nil, err
+		}
+		pieces = append(pieces, t)
+	}
+	return pieces, nil
+}
@@ -0,0 +1,104 @@
+package engine
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestBagRandomizer(t *testing.T) {
+	r := NewBagRandomizer(rand.New(rand.NewSource(1)))
+	for i := range 100 {
+		bag := make([]TetrominoType, len(allTetrominoes))
+		for j := range bag {
+			bag[j] = r.Next()
+		}
+		slices.Sort(bag)
+		if !slices.Equal(bag, allTetrominoes) {
+			t.Fatalf("bag %d holds %v", i, bag)
+		}
+	}
+}
+
+func TestHistoryRandomizerFirst(t *testing.T) {
+	for seed := range int64(500) {
+		switch p := NewHistoryRandomizer(rand.New(rand.NewSource(seed))).Next(); p {
+		case S, Z, O:
+			t.Fatalf("seed %d starts with %v", seed, p)
+		}
+	}
+}
+
+// counts deals n pieces from r and returns how often each came up and
+// how often a piece followed itself.
+func counts(r Randomizer, n int) (pieces map[TetrominoType]int, repeats int) {
+	pieces = make(map[TetrominoType]int)
+	last := TetrominoType(-1)
+	for range n {
+		p := r.Next()
+		pieces[p]++
+		if p == last {
+			repeats++
+		}
+		last = p
+	}
+	return pieces, repeats
+}
+
+func TestRandomizerDistribution(t *testing.T) {
+	const n = 7000
+	tests := []struct {
+		name       string
+		randomizer Randomizer
+		// maxRepeats bounds the pieces that follow themselves; a pure
+		// randomizer repeats one piece in seven.
+		maxRepeats int
+	}{
+		{"pure", NewPureRandomizer(rand.New(rand.NewSource(1))), n / 5},
+		{"history", NewHistoryRandomizer(rand.New(rand.NewSource(1))), n / 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pieces, repeats := counts(tt.randomizer, n)
+			for _, p := range allTetrominoes {
+				if got := pieces[p]; got < n/10 || got > n/5 {
+					t.Errorf("%v dealt %d times in %d", p, got, n)
+				}
+			}
+			if repeats > tt.maxRepeats {
+				t.Errorf("%d repeats, want at most %d", repeats, tt.maxRepeats)
+			}
+		})
+	}
+}
+
+func TestSequenceRandomizer(t *testing.T) {
+	r := NewSequenceRandomizer(S, Z, T)
+	var got []TetrominoType
+	for range 7 {
+		got = append(got, r.Next())
+	}
+	if want := []TetrominoType{S, Z, T, S, Z, T, S}; !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestParseSequence(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    []TetrominoType
+		wantErr bool
+	}{
+		{"IJLOSTZ", []TetrominoType{I, J, L, O, S, T, Z}, false},
+		{"tsz", []TetrominoType{T, S, Z}, false},
+		{"", []TetrominoType{}, false},
+		{"IX", nil, true},
+		{"I J", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseSequence(tt.in)
+		if (err != nil) != tt.wantErr || !slices.Equal(got, tt.want) {
+			t.Errorf("ParseSequence(%q) = %v, %v", tt.in, got, err)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// ReplayVersion is written to every replay and checked on load.
+const ReplayVersion = 4
+
+// ReplayInput records that the held buttons changed to Input on Tick. The
+// buttons stay held until the next change.
+type ReplayInput struct {
+	Tick  int   `json:"tick"`
+	Input Input `json:"input"`
+}
+
+// Replay is everything needed to play a game again tick for tick: the
+// seed, the rules, the player's handling and the input log.
+type Replay struct {
+	Version  int           `json:"version"`
+	Seed     int64         `json:"seed"`
+	Rules    Rules         `json:"rules"`
+	Handling Handling      `json:"handling"`
+	Ticks    int           `json:"ticks"`
+	Inputs   []ReplayInput `json:"inputs"`
+}
+
+func LoadReplay(path string) (*Replay, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var replay Replay
+	if err := json.Unmarshal(data, &replay); err != nil {
+		return nil, fmt.Errorf("engine: reading replay %s: %w", path, err)
+	}
+	if replay.Version != ReplayVersion {
+		return nil, fmt.Errorf("engine: replay %s has version %d, want %d", path, replay.Version, ReplayVersion)
+	}
+	if err := replay.Rules.Validate(); err != nil {
+		return nil, err
+	}
+	if err := replay.Handling.Validate(); err != nil {
+		return nil, err
+	}
+	return &replay, nil
+}
+
+func (replay *Replay) Save(path string) error {
+	data, err := json.Marshal(replay)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// NewGame starts the game the replay was recorded from.
+func (replay *Replay) NewGame() *Game {
+	game := NewGame(replay.Seed, replay.Rules)
+	game.SetHandling(replay.Handling)
+	return game
+}
+
+// Recorder logs the input of a game as it is played.
+type Recorder struct {
+	replay Replay
+	held   Input
+}
+
+// NewRecorder starts recording game, which must not have ticked yet.
+func NewRecorder(game *Game) *Recorder {
+	return &Recorder{replay: Replay{
+		Version:  ReplayVersion,
+		Seed:     game.Seed(),
+		Rules:    game.Rules(),
+		Handling: game.Handling(),
+	}}
+}
+
+// Record logs the buttons held on the next tick. Call it once per Tick.
+func (r *Recorder) Record(held Input) {
+	if held != r.held {
+		r.replay.Inputs = append(r.replay.Inputs, ReplayInput{Tick: r.replay.Ticks, Input: held})
+		r.held = held
+	}
+	r.replay.Ticks++
+}
+
+func (r *Recorder) Replay() *Replay {
+	replay := r.replay
+	replay.Inputs = append([]ReplayInput(nil), r.replay.Inputs...)
+	return &replay
+}
+
+// ReplayPlayer feeds a recorded input log back one tick at a time.
+type ReplayPlayer struct {
+	replay *Replay
+	tick   int
+	next   int
+	held   Input
+}
+
+func (replay *Replay) Player() *ReplayPlayer {
+	return &ReplayPlayer{replay: replay}
+}
+
+// Next returns the buttons held on the next tick. It returns false once
+// every recorded tick has been played.
+func (p *ReplayPlayer) Next() (Input, bool) {
+	if p.tick >= p.replay.Ticks {
+		return 0, false
+	}
+	if p.next < len(p.replay.Inputs) && p.replay.Inputs[p.next].Tick == p.tick {
+		p.held = p.replay.Inputs[p.next].Input
+		p.next++
+	}
+	p.tick++
+	return p.held, true
+}
+
+// Play runs the whole replay headlessly and returns the finished game.
+func (replay *Replay) Play() *Game {
+	game := replay.NewGame()
+	player := replay.Player()
+	for {
+		in, ok := player.Next()
+		if !ok {
+			return game
+		}
+		game.Tick(in)
+	}
+}
@@ -0,0 +1,50 @@
+package engine
+
+import (
+	"math/rand"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// Playing a recorded replay back must end in exactly the same game.
+func TestReplayDeterminism(t *testing.T) {
+	for _, mode := range Modes() {
+		t.Run(mode.Name, func(t *testing.T) {
+			game := NewGame(42, mode.Rules(DefaultRules()))
+			recorder := NewRecorder(game)
+			rng := rand.New(rand.NewSource(7))
+			var held Input
+			for tick := 0; tick < 5000 && !game.IsGameOver(); tick++ {
+				if rng.Intn(6) == 0 {
+					held = Input(rng.Intn(int(InputHardDrop) << 1))
+				}
+				recorder.Record(held)
+				game.Tick(held)
+			}
+
+			path := filepath.Join(t.TempDir(), "replay.json")
+			if err := recorder.Replay().Save(path); err != nil {
+				t.Fatal(err)
+			}
+			replay, err := LoadReplay(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := replay.Play()
+
+			if got.Frame() != game.Frame() || got.Score() != game.Score() ||
+				got.Lines() != game.Lines() || got.Pieces() != game.Pieces() {
+				t.Errorf("replay ended at frame %d with %d points, %d lines, %d pieces; want %d, %d, %d, %d",
+					got.Frame(), got.Score(), got.Lines(), got.Pieces(),
+					game.Frame(), game.Score(), game.Lines(), game.Pieces())
+			}
+			if !slices.EqualFunc(got.Board, game.Board, slices.Equal) {
+				t.Error("replay ended with a different board")
+			}
+			if game.Pieces() == 0 {
+				t.Error("recorded game locked no pieces")
+			}
+		})
+	}
+}
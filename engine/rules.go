@@ -0,0 +1,128 @@
+package engine
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// Rules selects the behaviour of a game. Start from DefaultRules; the
+// zero value is valid but locks pieces instantly and shows no next
+// pieces.
+type Rules struct {
+	// Mode names the mode the rules were made for; see Mode.
+	Mode string `json:"mode,omitempty"`
+	// LineGoal completes the game once this many lines are cleared. Zero
+	// plays until the board tops out.
+	LineGoal int `json:"line_goal,omitempty"`
+	// LevelGoal completes the game once this level has been cleared.
+	LevelGoal int `json:"level_goal,omitempty"`
+	// TimeLimit completes the game after this many ticks.
+	TimeLimit int `json:"time_limit,omitempty"`
+	// GarbageLines completes the game once this many garbage rows are
+	// cleared. At most GarbageHeight of them are on the board at once;
+	// the rest rise from the bottom as rows are cleared.
+	GarbageLines  int `json:"garbage_lines,omitempty"`
+	GarbageHeight int `json:"garbage_height,omitempty"`
+	// Messiness is the chance, from 0 to 1, that a garbage row has its
+	// hole in a different column from the row below it.
+	Messiness float64 `json:"messiness,omitempty"`
+	// Randomizer is one of RandomizerBag, RandomizerRandom,
+	// RandomizerHistory or RandomizerSequence. Empty means bag.
+	Randomizer string `json:"randomizer,omitempty"`
+	// Sequence lists the pieces dealt by the sequence randomizer.
+	Sequence []TetrominoType `json:"sequence,omitempty"`
+	// Gravity is one of GravityFixed, GravityGuideline or GravityNES and
+	// picks how fast pieces fall at each level. Empty means fixed.
+	Gravity string `json:"gravity,omitempty"`
+	// FixedG is the gravity under fixed gravity, in rows per tick. Zero
+	// means DefaultG.
+	FixedG float64 `json:"fixed_g,omitempty"`
+	// NextCount is how many upcoming pieces are revealed, up to
+	// MaxNextCount.
+	NextCount int `json:"next_count"`
+	// LockDelay is how many ticks a piece may rest on the ground before
+	// it locks.
+	LockDelay int `json:"lock_delay"`
+	// LockResets caps how often moving or rotating a grounded piece
+	// restarts its lock delay.
+	LockResets int `json:"lock_resets"`
+}
+
+const (
+	DefaultNextCount  = 5
+	MaxNextCount      = 6
+	DefaultLockDelay  = TicksPerSecond / 2
+	DefaultLockResets = 15
+)
+
+func DefaultRules() Rules {
+	return Rules{
+		Randomizer: RandomizerBag,
+		Gravity:    GravityGuideline,
+		NextCount:  DefaultNextCount,
+		LockDelay:  DefaultLockDelay,
+		LockResets: DefaultLockResets,
+		Messiness:  DefaultMessiness,
+	}
+}
+
+// DefaultG drops a piece a row every half second at TicksPerSecond.
+const DefaultG = 2.0 / TicksPerSecond
+
+// Validate reports whether the rules name known options.
+func (rules Rules) Validate() error {
+	switch rules.Randomizer {
+	case "", RandomizerBag, RandomizerRandom, RandomizerHistory:
+	case RandomizerSequence:
+		if len(rules.Sequence) == 0 {
+			return fmt.Errorf("engine: sequence randomizer needs at least one piece")
+		}
+	default:
+		return fmt.Errorf("engine: unknown randomizer %q", rules.Randomizer)
+	}
+	if rules.NextCount < 0 || rules.NextCount > MaxNextCount {
+		return fmt.Errorf("engine: next count %d outside 0-%d", rules.NextCount, MaxNextCount)
+	}
+	if rules.LockDelay < 0 || rules.LockResets < 0 {
+		return fmt.Errorf("engine: negative lock delay %d or resets %d", rules.LockDelay, rules.LockResets)
+	}
+	if rules.LineGoal < 0 || rules.LevelGoal < 0 || rules.TimeLimit < 0 || rules.GarbageLines < 0 {
+		return fmt.Errorf("engine: negative goal: %d lines, level %d, %d ticks or %d garbage lines",
+			rules.LineGoal, rules.LevelGoal, rules.TimeLimit, rules.GarbageLines)
+	}
+	if rules.GarbageHeight < 0 || rules.GarbageHeight >= BoardHeight {
+		return fmt.Errorf("engine: garbage height %d outside 0-%d", rules.GarbageHeight, BoardHeight-1)
+	}
+	if rules.Messiness < 0 || rules.Messiness > 1 {
+		return fmt.Errorf("engine: messiness %g outside 0-1", rules.Messiness)
+	}
+	switch rules.Gravity {
+	case "", GravityFixed, GravityGuideline, GravityNES:
+	default:
+		return fmt.Errorf("engine: unknown gravity %q", rules.Gravity)
+	}
+	if rules.FixedG < 0 || rules.FixedG > MaxG {
+		return fmt.Errorf("engine: fixed gravity %gG outside 0-%dG", rules.FixedG, MaxG)
+	}
+	return nil
+}
+
+// HasGoal reports whether the game ends at a goal rather than only when
+// the board tops out.
+func (rules Rules) HasGoal() bool {
+	return rules.LineGoal > 0 || rules.LevelGoal > 0 || rules.TimeLimit > 0 || rules.GarbageLines > 0
+}
+
+func (rules Rules) newRandomizer(r *rand.Rand) Randomizer {
+	switch rules.Randomizer {
+	case RandomizerRandom:
+		return NewPureRandomizer(r)
+	case RandomizerHistory:
+		return NewHistoryRandomizer(r)
+	case RandomizerSequence:
+		if len(rules.Sequence) > 0 {
+			return NewSequenceRandomizer(rules.Sequence...)
+		}
+	}
+	return NewBagRandomizer(r)
+}
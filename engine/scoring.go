@@ -0,0 +1,180 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TSpin classifies the spin that placed a T piece.
+type TSpin int
+
+const (
+	TSpinNone TSpin = iota
+	TSpinMini
+	TSpinFull
+)
+
+// EventKind identifies what an Event reports.
+type EventKind int
+
+const (
+	// EventLock is emitted every time a piece locks, with the rows it
+	// cleared and the points they scored.
+	EventLock EventKind = iota
+	// EventLevelUp is emitted when cleared lines raise the level.
+	EventLevelUp
+)
+
+// Event reports something that happened during a Tick. Drain them with
+// Game.Events.
+type Event struct {
+	Kind         EventKind
+	Frame        int
+	Piece        TetrominoType
+	Lines        int
+	TSpin        TSpin
+	BackToBack   bool
+	Combo        int
+	PerfectClear bool
+	Points       int
+	Level        int
+	// Attack is how many garbage lines the lock sends to an opponent,
+	// after canceling the garbage waiting to rise on this board.
+	Attack int
+}
+
+// Label describes an event for display, e.g. "Back-to-Back T-Spin
+// Double", "Combo 2" or "Level 3". It is empty for a plain lock.
+func (e Event) Label() string {
+	if e.Kind == EventLevelUp {
+		return fmt.Sprintf("Level %d", e.Level)
+	}
+	var parts []string
+	if e.BackToBack {
+		parts = append(parts, "Back-to-Back")
+	}
+	switch e.TSpin {
+	case TSpinMini:
+		parts = append(parts, "T-Spin Mini")
+	case TSpinFull:
+		parts = append(parts, "T-Spin")
+	}
+	if e.Lines > 0 {
+		parts = append(parts, clearNames[min(e.Lines, 4)])
+	}
+	label := strings.Join(parts, " ")
+	if e.Combo > 0 {
+		label += fmt.Sprintf("\nCombo %d", e.Combo)
+	}
+	if e.PerfectClear {
+		label += "\nPerfect Clear"
+	}
+	return label
+}
+
+var clearNames = [...]string{"", "Single", "Double", "Triple", "Tetris"}
+
+// Guideline points per level for line clears, indexed by rows cleared.
+var (
+	clearPoints     = [...]int{0, 100, 300, 500, 800}
+	miniTSpinPoints = [...]int{100, 200, 400}
+	tSpinPoints     = [...]int{400, 800, 1200, 1600}
+	perfectPoints   = [...]int{0, 800, 1200, 1800, 2000}
+)
+
+const (
+	comboPoints             = 50
+	backToBackPerfectTetris = 3200
+)
+
+// tSpin applies the three-corner rule to the current piece, which is about
+// to lock. Three of the four cells diagonal to the T's center must be
+// filled; if both on the side the T points to are, it is a full T-spin,
+// otherwise a mini unless the last rotation needed the final SRS kick.
+func (game *Game) tSpin() TSpin {
+	t := game.CurrentTetromino
+	if t.Type != T || !game.lastRotated {
+		return TSpinNone
+	}
+	cx, cy := t.X+1, t.Y+1
+	corners := [4][2]int{{-1, -1}, {1, -1}, {1, 1}, {-1, 1}}
+	filled := [4]bool{}
+	count := 0
+	for i, c := range corners {
+		filled[i] = game.Board.occupied(cx+c[0], cy+c[1])
+		if filled[i] {
+			count++
+		}
+	}
+	if count < 3 {
+		return TSpinNone
+	}
+	// The two corners either side of the pointing direction, as indexes
+	// into corners.
+	front := [4][2]int{R0: {0, 1}, R90: {1, 2}, R180: {2, 3}, R270: {3, 0}}[t.Rotation]
+	if filled[front[0]] && filled[front[1]] || game.lastKick == 4 {
+		return TSpinFull
+	}
+	return TSpinMini
+}
+
+// scoreClear awards the points for a lock that cleared lines rows with the
+// given spin and returns the event describing it.
+func (game *Game) scoreClear(lines int, spin TSpin) Event {
+	event := Event{
+		Kind:  EventLock,
+		Frame: game.frame,
+		Piece: game.CurrentTetromino.Type,
+		Lines: lines,
+		TSpin: spin,
+	}
+
+	var points int
+	switch spin {
+	case TSpinFull:
+		points = tSpinPoints[lines]
+	case TSpinMini:
+		points = miniTSpinPoints[min(lines, 2)]
+	default:
+		points = clearPoints[lines]
+	}
+
+	if lines > 0 {
+		difficult := lines == 4 || spin != TSpinNone
+		if difficult && game.backToBack {
+			event.BackToBack = true
+			points = points * 3 / 2
+		}
+		game.backToBack = difficult
+
+		game.combo++
+		event.Combo = game.combo
+		points += comboPoints * game.combo
+
+		if game.Board.IsEmpty() {
+			event.PerfectClear = true
+			if lines == 4 && event.BackToBack {
+				points += backToBackPerfectTetris
+			} else {
+				points += perfectPoints[lines]
+			}
+		}
+	} else {
+		game.combo = -1
+	}
+
+	event.Points = points * game.level
+	game.score += event.Points
+	return event
+}
+
+// Events returns the events emitted since the last call.
+func (game *Game) Events() []Event {
+	events := game.events
+	game.events = nil
+	return events
+}
+
+func (game *Game) emit(e Event) {
+	game.events = append(game.events, e)
+}
@@ -0,0 +1,92 @@
+package engine
+
+import "testing"
+
+func TestTSpin(t *testing.T) {
+	// Both corners below the T's center at (4, 18) are filled, and the
+	// top-left one is added per case.
+	slot := testBoard("####.#####")
+	tests := []struct {
+		name     string
+		piece    TetrominoType
+		rotation Rotation
+		topLeft  bool
+		rotated  bool
+		kick     int
+		want     TSpin
+	}{
+		{"pointing into the slot", T, R180, true, true, 0, TSpinFull},
+		{"pointing away", T, R0, true, true, 0, TSpinMini},
+		{"pointing away after the last kick", T, R0, true, true, 4, TSpinFull},
+		{"two corners", T, R180, false, true, 0, TSpinNone},
+		{"not rotated", T, R180, true, false, 0, TSpinNone},
+		{"not a T", S, R180, true, true, 0, TSpinNone},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := NewGame(1, testRules(T))
+			game.Board = slot.Copy()
+			if tt.topLeft {
+				game.Board[17][3] = Garbage
+			}
+			game.CurrentTetromino = &Tetromino{Type: tt.piece, Rotation: tt.rotation, X: 3, Y: 17}
+			game.lastRotated = tt.rotated
+			game.lastKick = tt.kick
+			if got := game.tSpin(); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScoreClear(t *testing.T) {
+	tests := []struct {
+		name       string
+		lines      int
+		spin       TSpin
+		backToBack bool
+		want       int
+		wantB2B    bool
+	}{
+		{"nothing", 0, TSpinNone, false, 0, false},
+		{"single", 1, TSpinNone, false, 100, false},
+		{"tetris", 4, TSpinNone, false, 800, false},
+		{"back-to-back tetris", 4, TSpinNone, true, 1200, true},
+		{"single breaks back-to-back", 1, TSpinNone, true, 100, false},
+		{"T-spin", 0, TSpinFull, false, 400, false},
+		{"T-spin single", 1, TSpinFull, false, 800, false},
+		{"T-spin double", 2, TSpinFull, false, 1200, false},
+		{"back-to-back T-spin double", 2, TSpinFull, true, 1800, true},
+		{"T-spin triple", 3, TSpinFull, false, 1600, false},
+		{"T-spin mini", 0, TSpinMini, false, 100, false},
+		{"T-spin mini single", 1, TSpinMini, false, 200, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := NewGame(1, testRules(T))
+			game.Board = testBoard("#.........")
+			game.backToBack = tt.backToBack
+			event := game.scoreClear(tt.lines, tt.spin)
+			if event.Points != tt.want {
+				t.Errorf("scored %d, want %d", event.Points, tt.want)
+			}
+			if event.BackToBack != tt.wantB2B {
+				t.Errorf("back-to-back %v, want %v", event.BackToBack, tt.wantB2B)
+			}
+		})
+	}
+}
+
+func TestScoreCombo(t *testing.T) {
+	game := NewGame(1, testRules(T))
+	game.Board = testBoard("#.........")
+	for i, want := range []int{100, 150, 200, 0, 100} {
+		lines := 1
+		if want == 0 {
+			lines = 0
+		}
+		if got := game.scoreClear(lines, TSpinNone).Points; got != want {
+			t.Errorf("lock %d scored %d, want %d", i, got, want)
+		}
+	}
+}
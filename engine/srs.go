@@ -0,0 +1,74 @@
+package engine
+
+// kick is an (x, y) offset tried when a rotation collides. As in the SRS
+// specification y points up, so it is negated before being applied.
+type kick [2]int
+
+type rotationKey struct {
+	from, to Rotation
+}
+
+// Wall kick data for J, L, S, T and Z. O never kicks.
+var jlstzKicks = map[rotationKey][5]kick{
+	{R0, R90}:    {{0, 0}, {-1, 0}, {-1, 1}, {0, -2}, {-1, -2}},
+	{R90, R0}:    {{0, 0}, {1, 0}, {1, -1}, {0, 2}, {1, 2}},
+	{R90, R180}:  {{0, 0}, {1, 0}, {1, -1}, {0, 2}, {1, 2}},
+	{R180, R90}:  {{0, 0}, {-1, 0}, {-1, 1}, {0, -2}, {-1, -2}},
+	{R180, R270}: {{0, 0}, {1, 0}, {1, 1}, {0, -2}, {1, -2}},
+	{R270, R180}: {{0, 0}, {-1, 0}, {-1, -1}, {0, 2}, {-1, 2}},
+	{R270, R0}:   {{0, 0}, {-1, 0}, {-1, -1}, {0, 2}, {-1, 2}},
+	{R0, R270}:   {{0, 0}, {1, 0}, {1, 1}, {0, -2}, {1, -2}},
+}
+
+// Wall kick data for I.
+var iKicks = map[rotationKey][5]kick{
+	{R0, R90}:    {{0, 0}, {-2, 0}, {1, 0}, {-2, -1}, {1, 2}},
+	{R90, R0}:    {{0, 0}, {2, 0}, {-1, 0}, {2, 1}, {-1, -2}},
+	{R90, R180}:  {{0, 0}, {-1, 0}, {2, 0}, {-1, 2}, {2, -1}},
+	{R180, R90}:  {{0, 0}, {1, 0}, {-2, 0}, {1, -2}, {-2, 1}},
+	{R180, R270}: {{0, 0}, {2, 0}, {-1, 0}, {2, 1}, {-1, -2}},
+	{R270, R180}: {{0, 0}, {-2, 0}, {1, 0}, {-2, -1}, {1, 2}},
+	{R270, R0}:   {{0, 0}, {1, 0}, {-2, 0}, {1, -2}, {-2, 1}},
+	{R0, R270}:   {{0, 0}, {-1, 0}, {2, 0}, {-1, 2}, {2, -1}},
+}
+
+// kicks returns the offsets to try, in order, when rotating t from one
+// orientation to another.
+func kicks(t TetrominoType, from, to Rotation) []kick {
+	switch t {
+	case O:
+		return []kick{{0, 0}}
+	case I:
+		k := iKicks[rotationKey{from, to}]
+		return k[:]
+	default:
+		k := jlstzKicks[rotationKey{from, to}]
+		return k[:]
+	}
+}
+
+// Rotate turns t to the given orientation with SRS kicks, as the game
+// does, and reports whether any kick fit. A t that cannot turn is left
+// untouched.
+func (b Board) Rotate(t *Tetromino, to Rotation) bool {
+	return b.rotate(t, to) >= 0
+}
+
+// rotate turns t to the given orientation, trying each SRS kick in turn.
+// It returns the index of the kick that succeeded, or -1 if every kick
+// collided, in which case t is left untouched.
+func (b Board) rotate(t *Tetromino, to Rotation) int {
+	from := t.Rotation
+	x, y := t.X, t.Y
+	for i, k := range kicks(t.Type, from, to) {
+		t.Rotation = to
+		t.X = x + k[0]
+		t.Y = y - k[1]
+		if !b.Collides(t) {
+			return i
+		}
+	}
+	t.Rotation = from
+	t.X, t.Y = x, y
+	return -1
+}
@@ -0,0 +1,84 @@
+package engine
+
+import "testing"
+
+func TestRotateKicks(t *testing.T) {
+	full := NewBoard()
+	for y := range full {
+		for x := range full[y] {
+			full[y][x] = Garbage
+		}
+	}
+	for _, c := range [][2]int{{4, 17}, {3, 18}, {4, 18}, {5, 18}} {
+		full[c[1]][c[0]] = Empty
+	}
+
+	tests := []struct {
+		name     string
+		board    Board
+		piece    Tetromino
+		to       Rotation
+		wantKick int
+		want     Tetromino
+	}{
+		{
+			name:     "open space",
+			board:    NewBoard(),
+			piece:    Tetromino{Type: T, Rotation: R0, X: 3, Y: 5},
+			to:       R90,
+			wantKick: 0,
+			want:     Tetromino{Type: T, Rotation: R90, X: 3, Y: 5},
+		},
+		{
+			name:     "left wall",
+			board:    NewBoard(),
+			piece:    Tetromino{Type: T, Rotation: R90, X: -1, Y: 5},
+			to:       R0,
+			wantKick: 1,
+			want:     Tetromino{Type: T, Rotation: R0, X: 0, Y: 5},
+		},
+		{
+			name:     "T off the floor",
+			board:    NewBoard(),
+			piece:    Tetromino{Type: T, Rotation: R0, X: 3, Y: 18},
+			to:       R90,
+			wantKick: 2,
+			want:     Tetromino{Type: T, Rotation: R90, X: 2, Y: 17},
+		},
+		{
+			name:     "I off the floor",
+			board:    NewBoard(),
+			piece:    Tetromino{Type: I, Rotation: R0, X: 3, Y: 18},
+			to:       R90,
+			wantKick: 4,
+			want:     Tetromino{Type: I, Rotation: R90, X: 4, Y: 16},
+		},
+		{
+			name:     "O does not kick",
+			board:    NewBoard(),
+			piece:    Tetromino{Type: O, Rotation: R0, X: 3, Y: 5},
+			to:       R90,
+			wantKick: 0,
+			want:     Tetromino{Type: O, Rotation: R90, X: 3, Y: 5},
+		},
+		{
+			name:     "boxed in",
+			board:    full,
+			piece:    Tetromino{Type: T, Rotation: R0, X: 3, Y: 17},
+			to:       R90,
+			wantKick: -1,
+			want:     Tetromino{Type: T, Rotation: R0, X: 3, Y: 17},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.piece
+			if kick := tt.board.rotate(&got, tt.to); kick != tt.wantKick {
+				t.Errorf("kick %d, want %d", kick, tt.wantKick)
+			}
+			if got != tt.want {
+				t.Errorf("piece %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
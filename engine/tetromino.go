@@ -0,0 +1,131 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TetrominoType identifies one of the seven tetrominoes.
+type TetrominoType int
+
+const (
+	I TetrominoType = iota
+	J
+	L
+	O
+	S
+	T
+	Z
+)
+
+const tetrominoNames = "IJLOSTZ"
+
+func (t TetrominoType) String() string {
+	if t < 0 || int(t) >= len(tetrominoNames) {
+		return fmt.Sprintf("TetrominoType(%d)", int(t))
+	}
+	return tetrominoNames[t : t+1]
+}
+
+// ParseTetrominoType returns the piece named by a single letter.
+func ParseTetrominoType(s string) (TetrominoType, error) {
+	i := strings.Index(tetrominoNames, strings.ToUpper(s))
+	if len(s) != 1 || i < 0 {
+		return 0, fmt.Errorf("engine: unknown tetromino %q", s)
+	}
+	return TetrominoType(i), nil
+}
+
+func (t TetrominoType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+func (t *TetrominoType) UnmarshalText(text []byte) error {
+	p, err := ParseTetrominoType(string(text))
+	if err != nil {
+		return err
+	}
+	*t = p
+	return nil
+}
+
+// Rotation is the orientation of a tetromino, clockwise from spawn.
+type Rotation int
+
+const (
+	R0 Rotation = iota
+	R90
+	R180
+	R270
+)
+
+// Tetromino is a piece on the board. X and Y are the board coordinates of
+// the top-left corner of its shape.
+type Tetromino struct {
+	Type     TetrominoType
+	Rotation Rotation
+	X, Y     int
+}
+
+// tetrominoShapes holds the shape of every piece in each of its rotations.
+// The boxes and orientations follow the Super Rotation System, which the
+// kick tables in srs.go rely on.
+var tetrominoShapes = map[TetrominoType][][][]int{
+	I: {
+		{{0, 0, 0, 0}, {1, 1, 1, 1}, {0, 0, 0, 0}, {0, 0, 0, 0}},
+		{{0, 0, 1, 0}, {0, 0, 1, 0}, {0, 0, 1, 0}, {0, 0, 1, 0}},
+		{{0, 0, 0, 0}, {0, 0, 0, 0}, {1, 1, 1, 1}, {0, 0, 0, 0}},
+		{{0, 1, 0, 0}, {0, 1, 0, 0}, {0, 1, 0, 0}, {0, 1, 0, 0}},
+	},
+	J: {
+		{{1, 0, 0}, {1, 1, 1}, {0, 0, 0}},
+		{{0, 1, 1}, {0, 1, 0}, {0, 1, 0}},
+		{{0, 0, 0}, {1, 1, 1}, {0, 0, 1}},
+		{{0, 1, 0}, {0, 1, 0}, {1, 1, 0}},
+	},
+	L: {
+		{{0, 0, 1}, {1, 1, 1}, {0, 0, 0}},
+		{{0, 1, 0}, {0, 1, 0}, {0, 1, 1}},
+		{{0, 0, 0}, {1, 1, 1}, {1, 0, 0}},
+		{{1, 1, 0}, {0, 1, 0}, {0, 1, 0}},
+	},
+	O: {
+		{{0, 1, 1, 0}, {0, 1, 1, 0}, {0, 0, 0, 0}},
+		{{0, 1, 1, 0}, {0, 1, 1, 0}, {0, 0, 0, 0}},
+		{{0, 1, 1, 0}, {0, 1, 1, 0}, {0, 0, 0, 0}},
+		{{0, 1, 1, 0}, {0, 1, 1, 0}, {0, 0, 0, 0}},
+	},
+	S: {
+		{{0, 1, 1}, {1, 1, 0}, {0, 0, 0}},
+		{{0, 1, 0}, {0, 1, 1}, {0, 0, 1}},
+		{{0, 0, 0}, {0, 1, 1}, {1, 1, 0}},
+		{{1, 0, 0}, {1, 1, 0}, {0, 1, 0}},
+	},
+	T: {
+		{{0, 1, 0}, {1, 1, 1}, {0, 0, 0}},
+		{{0, 1, 0}, {0, 1, 1}, {0, 1, 0}},
+		{{0, 0, 0}, {1, 1, 1}, {0, 1, 0}},
+		{{0, 1, 0}, {1, 1, 0}, {0, 1, 0}},
+	},
+	Z: {
+		{{1, 1, 0}, {0, 1, 1}, {0, 0, 0}},
+		{{0, 0, 1}, {0, 1, 1}, {0, 1, 0}},
+		{{0, 0, 0}, {1, 1, 0}, {0, 1, 1}},
+		{{0, 1, 0}, {1, 1, 0}, {1, 0, 0}},
+	},
+}
+
+// NewTetromino returns a piece of the given type in spawn position.
+func NewTetromino(t TetrominoType) *Tetromino {
+	return &Tetromino{
+		Type:     t,
+		Rotation: R0,
+		X:        3,
+		Y:        0,
+	}
+}
+
+// Shape returns the cells of the piece in its current rotation.
+func (t *Tetromino) Shape() [][]int {
+	return tetrominoShapes[t.Type][t.Rotation]
+}
@@ -2,202 +2,336 @@ package main
 
 import (
 	"fmt"
-	"math/rand"
+	"log"
 	"os"
-	"os/exec"
+	"strings"
 	"time"
+	"unicode"
+
+	"tetris-game/engine"
+	"tetris-game/session"
+	"tetris-game/spectate"
 )
 
+// Markers for the active piece and its ghost in the render copy of the
+// board. They are negative so they never clash with engine cells.
 const (
-	boardWidth  = 10
-    boardHeight = 20
-    fallSpeed = 500
+	activeCell engine.Cell = -1
+	ghostCell  engine.Cell = -2
 )
 
-type Game struct {
-	Board    [][]int
-	CurrentTetromino *Tetromino
-	gameOver bool
-	score int
-	lastFallTime time.Time
+// pieceColors are the guideline colors as ANSI SGR parameters.
+var pieceColors = map[engine.TetrominoType]string{
+	engine.I: "96",
+	engine.J: "94",
+	engine.L: "38;5;208",
+	engine.O: "93",
+	engine.S: "92",
+	engine.T: "95",
+	engine.Z: "91",
 }
 
-func NewGame() *Game {
-	rand.Seed(time.Now().UnixNano())
-	board := make([][]int, boardHeight)
-	for i := range board {
-		board[i] = make([]int, boardWidth)
-	}
-    
-	return &Game{
-        Board:    board,
-		CurrentTetromino: NewTetromino(),
-		gameOver: false,
-		score: 0,
-		lastFallTime: time.Now(),
-    }
+const (
+	garbageColor = "2;37"
+	spentColor   = "90"
+)
+
+type Game struct {
+	session *session.Session
+	// recordPath receives the replay of every game as it ends.
+	recordPath string
+
+	// announcement is the label of the last scoring event of the game
+	// announced, printed under the score until the next one.
+	announcement string
+	announced    *engine.Game
+	screen       screen
+	// publisher streams every tick to viewers, if set.
+	publisher *spectate.Publisher
 }
 
+func NewGame(s *session.Session, recordPath string) *Game {
+	return &Game{session: s, recordPath: recordPath}
+}
 
-func (game *Game) MoveLeft() {
-    game.CurrentTetromino.X -= 1
-	if game.IsCollision() {
-        game.CurrentTetromino.X += 1
-    }
+// Run drives the session at engine.TicksPerSecond until a quit key is
+// pressed or a replay ends.
+func (game *Game) Run(keys <-chan string) {
+	ticker := time.NewTicker(time.Second / engine.TicksPerSecond)
+	defer ticker.Stop()
+	kb := newKeyboard()
+	var in session.Input
+	game.Drawboard()
+	for {
+		select {
+		case key, ok := <-keys:
+			if !ok || key == ctrlC || quitKeys[key] && game.session.State != session.HighScoreEntry {
+				return
+			}
+			if button, ok := keyBindings[key]; ok {
+				kb.Press(button)
+			}
+			if cmd, ok := commandKeys[key]; ok {
+				in.Commands = append(in.Commands, cmd)
+			}
+			if r := []rune(key); len(r) == 1 && unicode.IsPrint(r[0]) {
+				in.Typed = append(in.Typed, r[0])
+			}
+		case <-ticker.C:
+			in.Held = kb.Held()
+			game.GameTick(in)
+			in = session.Input{}
+			if game.session.State != session.Playing {
+				// Keys pressed in menus must not carry into the game.
+				kb = newKeyboard()
+			}
+			if game.session.Replaying() && game.session.State == session.Title {
+				return
+			}
+		}
+	}
 }
 
-func (game *Game) MoveRight() {
-	game.CurrentTetromino.X += 1
-	if game.IsCollision() {
-        game.CurrentTetromino.X -= 1
+// GameTick advances the session by one tick and redraws it.
+func (game *Game) GameTick(in session.Input) {
+	state := game.session.State
+	if game.session.Game != game.announced {
+		game.announced = game.session.Game
+		game.announcement = ""
+	}
+	events := game.session.Update(in)
+	if game.publisher != nil && game.session.Game != nil {
+		game.publisher.Publish(game.session.Game, events)
+	}
+	var labels []string
+	for _, e := range events {
+		if label := e.Label(); label != "" {
+			labels = append(labels, label)
+		}
+	}
+	if len(labels) > 0 {
+		game.announcement = strings.Join(labels, "\n")
 	}
+	if state == session.Playing && game.session.State == session.GameOver {
+		game.saveReplay()
+	}
+	game.Drawboard()
 }
 
-func (game *Game) MoveDown() {
-	game.CurrentTetromino.Y += 1
-	if game.IsCollision() {
-        game.CurrentTetromino.Y -= 1 // 撞击之后退回到原来的位置
-		game.FreezeTetromino()
-		game.ClearLines()
-		game.CurrentTetromino = NewTetromino()
+// saveReplay writes the last game recorded to recordPath.
+func (game *Game) saveReplay() {
+	if game.session.Recorder == nil || game.recordPath == "" {
+		return
+	}
+	if err := game.session.Recorder.Replay().Save(game.recordPath); err != nil {
+		log.Print(err)
+	}
+}
 
-		if game.IsCollision() {
-			game.gameOver = true
+// Drawboard redraws the screen in place from the top left corner.
+func (game *Game) Drawboard() {
+	var lines []string
+	s := game.session
+	switch s.State {
+	case session.Title:
+		lines = append(lines, "TETRIS", "")
+		lines = append(lines, menuLines(s)...)
+		lines = append(lines, "", "↑/↓ choose  ←/→ change  Enter select  l high scores  x quit")
+	case session.Paused:
+		lines = append(lines, "PAUSED", "")
+		lines = append(lines, menuLines(s)...)
+		lines = append(lines, "", "p resume  r restart  x quit")
+	case session.HighScores:
+		var b strings.Builder
+		printLeaderboard(&b, s.Scores, s.Settings.Mode, s.Rank)
+		lines = append(lines, strings.Split(strings.TrimRight(b.String(), "\n"), "\n")...)
+		lines = append(lines, "", "Enter title  r play again  x quit")
+	case session.GameOver:
+		if s.Game.Completed() {
+			lines = s.Results()
+			break
+		}
+		lines = append(game.boardLines(s), strings.Join(s.Results(), "  "))
+	default:
+		lines = game.boardLines(s)
+		switch s.State {
+		case session.Playing:
+			lines = append(lines, controlsHelp)
+		case session.HighScoreEntry:
+			lines = append(lines, "New high score! Name: "+s.Name+"_")
 		}
 	}
+
+	game.screen.draw(lines)
 }
 
+// screen redraws the terminal in place from the top left corner. It keeps
+// the last frame drawn, so unchanged frames are not sent again.
+type screen struct {
+	last string
+}
 
+func (s *screen) draw(lines []string) {
+	var b strings.Builder
+	for _, line := range lines {
+		b.WriteString(line + clearLine + "\r\n")
+	}
+	frame := b.String()
+	if frame == s.last {
+		return
+	}
+	s.last = frame
+	fmt.Fprint(os.Stdout, cursorHome+frame+clearBelow)
+}
 
-func (game *Game) RotateRight() {
-	oldShape := game.CurrentTetromino.Shape
-	game.CurrentTetromino.RotateRight()
-	if game.IsCollision() {
-		game.CurrentTetromino.Shape = oldShape
-		game.CurrentTetromino.RotateLeft()
+func menuLines(s *session.Session) []string {
+	items, cursor := s.Menu()
+	lines := make([]string, len(items))
+	for i, item := range items {
+		mark := "  "
+		if i == cursor {
+			mark = "> "
+		}
+		lines[i] = mark + item
 	}
+	return lines
 }
 
-func (game *Game) RotateLeft() {
-	oldShape := game.CurrentTetromino.Shape
-	game.CurrentTetromino.RotateLeft()
-	if game.IsCollision() {
-		game.CurrentTetromino.Shape = oldShape
-		game.CurrentTetromino.RotateRight()
+// boardLines renders the score, the board and the side panel, with the
+// timer and splits of modes that have them.
+func (game *Game) boardLines(s *session.Session) []string {
+	g := s.Game
+	lines := []string{fmt.Sprintf("Score: %d  Level: %d  Lines: %d", g.Score(), g.Level(), g.Lines())}
+	if stats := s.Stats(); len(stats) > 0 {
+		lines = append(lines, strings.Join(stats, "  "), strings.Join(s.Splits(), "  "))
 	}
+	lines = append(lines, strings.ReplaceAll(game.announcement, "\n", ", "))
+
+	ghost := g.Ghost()
+	panel := sidePanel(g)
+	return append(lines, boardRows(g.Board, g.CurrentTetromino, &ghost, panel)...)
 }
 
-func (game *Game) IsCollision() bool {
-	for y, row := range game.CurrentTetromino.Shape {
-		for x, cell := range row {
-			if cell == 1 {
-				boardX := game.CurrentTetromino.X + x
-				boardY := game.CurrentTetromino.Y + y
-				
-                //check out of boundry
-				if boardX < 0 || boardX >= boardWidth || boardY >= boardHeight{
-					return true
-				}
+// boardRows renders board with piece and its ghost on it and panel to the
+// right.
+func boardRows(board engine.Board, piece, ghost *engine.Tetromino, panel []string) []string {
+	//Copy board for render
+	tempBoard := board.Copy()
 
-				if boardY >= 0 && game.Board[boardY][boardX] == 1 {
-					return true
-				}
+	markPiece(tempBoard, ghost, ghostCell)
+	markPiece(tempBoard, piece, activeCell)
 
+	color := pieceColors[piece.Type]
+	var lines []string
+	for y, row := range tempBoard {
+		var s strings.Builder
+		s.WriteString("|")
+		for _, cell := range row {
+			switch cell {
+			case engine.Empty:
+				s.WriteString(" .")
+			case activeCell:
+				s.WriteString(colored(color, "██"))
+			case ghostCell:
+				s.WriteString(colored(color, "░░"))
+			case engine.Garbage:
+				s.WriteString(colored(garbageColor, "▓▓"))
+			default:
+				t, _ := cell.Piece()
+				s.WriteString(colored(pieceColors[t], "██"))
 			}
 		}
+		s.WriteString("|")
+		if y < len(panel) {
+			s.WriteString("   " + panel[y])
+		}
+		lines = append(lines, s.String())
 	}
-	return false
+	lines = append(lines, "+"+strings.Repeat("--", engine.BoardWidth)+"+")
+	for y := len(tempBoard); y < len(panel); y++ {
+		lines = append(lines, fmt.Sprintf("%*s   %s", 2*engine.BoardWidth+2, "", panel[y]))
+	}
+	return lines
 }
 
+func colored(color, s string) string {
+	return "\x1b[" + color + "m" + s + resetColor
+}
 
-func (game *Game) FreezeTetromino() {
-	for y, row := range game.CurrentTetromino.Shape {
+func markPiece(board engine.Board, piece *engine.Tetromino, mark engine.Cell) {
+	for y, row := range piece.Shape() {
 		for x, cell := range row {
 			if cell == 1 {
-                gameY := game.CurrentTetromino.Y + y
-				gameX := game.CurrentTetromino.X + x
-				if gameY >= 0 {
-                	game.Board[gameY][gameX] = 1
+				boardX := piece.X + x
+				boardY := piece.Y + y
+				if boardY >= 0 && boardY < engine.BoardHeight {
+					board[boardY][boardX] = mark
 				}
 			}
 		}
 	}
 }
 
-func (game *Game) ClearLines() {
-	for y := 0; y < boardHeight; y++ {
-		fullLine := true // 一行默认是满的
-        for x := 0; x < boardWidth; x++ {
-           if game.Board[y][x] == 0 {
-			   fullLine=false
-			   break
-		   }
-        }
+// sidePanel returns the lines printed to the right of the board.
+func sidePanel(game *engine.Game) []string {
+	hold, ok := game.HoldPiece()
+	var held []engine.TetrominoType
+	if ok {
+		held = append(held, hold)
+	}
+	return panelLines(held, game.CanHold(), game.Next())
+}
 
-        if fullLine {
-			//删除该行，并将上面的所有行往下移动一行
-			game.score = game.score + 100
-			for i:=y; i> 0; i-- {
-				game.Board[i] = game.Board[i-1]
+// panelLines renders the hold slot, which holds at most one piece, and
+// the next queue.
+func panelLines(hold []engine.TetrominoType, canHold bool, next []engine.TetrominoType) []string {
+	lines := []string{"Hold:"}
+	for _, t := range hold {
+		color := pieceColors[t]
+		if !canHold {
+			color = spentColor
+		}
+		lines = append(lines, pieceLines(t, color)...)
+	}
+	if len(next) > 0 {
+		lines = append(lines, "", "Next:")
+		for i, t := range next {
+			if i > 0 {
+				lines = append(lines, "")
 			}
-			game.Board[0] = make([]int, boardWidth)
-			y--
+			lines = append(lines, pieceLines(t, pieceColors[t])...)
 		}
 	}
+	return lines
 }
 
-func (game *Game) Drawboard() {
-	ClearScreen()
-    fmt.Println("Score: ", game.score)
-	//Copy board for render
-	tempBoard := make([][]int, boardHeight);
-	for i := range tempBoard {
-		tempBoard[i] = make([]int, boardWidth)
-		copy(tempBoard[i], game.Board[i])
-	}
-
-	for y, row := range game.CurrentTetromino.Shape {
+// pieceLines renders the spawn orientation of t, skipping empty rows and
+// columns.
+func pieceLines(t engine.TetrominoType, color string) []string {
+	shape := (&engine.Tetromino{Type: t}).Shape()
+	left := len(shape[0])
+	for _, row := range shape {
 		for x, cell := range row {
-			if cell == 1 {
-				boardX := game.CurrentTetromino.X + x
-				boardY := game.CurrentTetromino.Y + y
-				if boardY >= 0 && boardY < boardHeight {
-					tempBoard[boardY][boardX]=2
-				}
+			if cell != 0 && x < left {
+				left = x
 			}
 		}
 	}
-
-
-	for _,row := range tempBoard{
-		for _, cell := range row {
-            if cell == 0 {
-                fmt.Print(". ")    
-            } else if cell == 1 {
-                fmt.Print("# ")
-            } else {
-                fmt.Print("* ")
-            }
+	var lines []string
+	for _, row := range shape {
+		line := ""
+		empty := true
+		for _, cell := range row[left:] {
+			if cell != 0 {
+				line += colored(color, "██")
+				empty = false
+			} else {
+				line += "  "
+			}
+		}
+		if !empty {
+			lines = append(lines, line)
 		}
-		fmt.Println()
-	}
-	fmt.Println()
-}
-
-func ClearScreen() {
-	cmd := exec.Command("clear")
-	cmd.Stdout = os.Stdout // 将输出重定向到控制台
-	cmd.Run()             // 执行命令
-}
-
-func (game *Game) GameTick() {
-	if time.Since(game.lastFallTime).Milliseconds() >= fallSpeed {
-		game.MoveDown()
-		game.lastFallTime = time.Now()
 	}
-	game.Drawboard()
-}
-
-func (game *Game) IsGameOver() bool {
-	return game.gameOver
+	return lines
 }
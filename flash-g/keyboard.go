@@ -0,0 +1,104 @@
+package main
+
+import (
+	"tetris-game/engine"
+	"tetris-game/session"
+)
+
+// keyBindings maps the keys read in raw mode to the buttons they press.
+var keyBindings = map[string]engine.Input{
+	"a":      engine.InputLeft,
+	"\x1b[D": engine.InputLeft,
+	"d":      engine.InputRight,
+	"\x1b[C": engine.InputRight,
+	"s":      engine.InputSoftDrop,
+	"\x1b[B": engine.InputSoftDrop,
+	"r":      engine.InputRotateRight,
+	"\x1b[A": engine.InputRotateRight,
+	"l":      engine.InputRotateLeft,
+	"z":      engine.InputRotateLeft,
+	"c":      engine.InputHold,
+	"w":      engine.InputHardDrop,
+	" ":      engine.InputHardDrop,
+}
+
+// commandKeys maps keys to the menu commands of the session. They share
+// keys with the game; the session only listens to them off the board.
+var commandKeys = map[string]session.Command{
+	"\x1b[A": session.CommandUp,
+	"w":      session.CommandUp,
+	"\x1b[B": session.CommandDown,
+	"s":      session.CommandDown,
+	"\x1b[D": session.CommandLeft,
+	"a":      session.CommandLeft,
+	"\x1b[C": session.CommandRight,
+	"d":      session.CommandRight,
+	"\r":     session.CommandConfirm,
+	"\n":     session.CommandConfirm,
+	"\x1b":   session.CommandBack,
+	"p":      session.CommandPause,
+	"l":      session.CommandScores,
+	"r":      session.CommandRestart,
+	"\x7f":   session.CommandDelete,
+	"\b":     session.CommandDelete,
+}
+
+// quitKeys leave flash-g, except while a name is typed. Ctrl-C always
+// does.
+var quitKeys = map[string]bool{"x": true, "q": true}
+
+const ctrlC = "\x03"
+
+const controlsHelp = "a/d/←/→ move  s/↓ soft drop  w/space hard drop  r/↑ l/z rotate  c hold  p pause  x quit"
+
+// tapButtons act once per key press, so every press is played as its own
+// tick with the button down.
+const tapButtons = engine.InputRotateRight | engine.InputRotateLeft | engine.InputHold | engine.InputHardDrop
+
+// releaseTicks is how long a movement button counts as held after its key
+// was last seen. Terminals report no key releases, only repeats, so this
+// must outlast the gap between the key repeats.
+const releaseTicks = 4
+
+// keyboard turns key presses into the held buttons the engine expects.
+type keyboard struct {
+	tick int
+	seen map[engine.Input]int // tick a movement key was last seen
+	taps map[engine.Input]int // tap presses not played yet
+	held engine.Input
+}
+
+func newKeyboard() *keyboard {
+	return &keyboard{
+		seen: make(map[engine.Input]int),
+		taps: make(map[engine.Input]int),
+	}
+}
+
+func (kb *keyboard) Press(in engine.Input) {
+	if in&tapButtons != 0 {
+		kb.taps[in]++
+	} else {
+		kb.seen[in] = kb.tick
+	}
+}
+
+// Held returns the buttons to hold on the next tick. A tap button is
+// released for a tick between presses so each one is a new press.
+func (kb *keyboard) Held() engine.Input {
+	kb.tick++
+	var held engine.Input
+	for in, tick := range kb.seen {
+		if kb.tick-tick <= releaseTicks {
+			held |= in
+		}
+	}
+	for in, n := range kb.taps {
+		if n > 0 && kb.held&in == 0 {
+			held |= in
+			kb.taps[in]--
+		}
+	}
+	kb.held = held
+	return held
+}
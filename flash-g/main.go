@@ -1,34 +1,112 @@
 package main
 
 import (
-	"bufio"
+	"flag"
 	"fmt"
+	"log"
+	"net"
 	"os"
+
+	"tetris-game/cli"
+	"tetris-game/engine"
+	"tetris-game/highscore"
+	"tetris-game/session"
+	"tetris-game/spectate"
 )
 
 func main() {
-	game := NewGame()
-    input := bufio.NewReader(os.Stdin)
+	rules := engine.DefaultRules()
+	var seed int64
+	cli.RulesFlags(flag.CommandLine, &rules, &seed)
+	record := flag.String("record", "", "write a replay of the game to this file")
+	replayPath := flag.String("replay", "", "play back a replay file instead of a live game")
+	showScores := flag.Bool("scores", false, "print the high score table and exit")
+	publish := flag.String("publish", "", "stream the game to viewers on this TCP address, e.g. :"+spectate.DefaultPort)
+	watchAddr := flag.String("watch", "", "watch the game published at host:port instead of playing")
+	handling := engine.DefaultHandling()
+	cli.HandlingFlags(flag.CommandLine, &handling)
+	var bot cli.Bot
+	cli.BotFlags(flag.CommandLine, &bot)
+	flag.Parse()
+	if err := rules.Validate(); err != nil {
+		log.Fatal(err)
+	}
+	if err := handling.Validate(); err != nil {
+		log.Fatal(err)
+	}
+	scores, scoresPath, err := highscore.LoadDefault()
+	if err != nil {
+		log.Print(err)
+	}
+	if *watchAddr != "" {
+		addr := *watchAddr
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			addr = net.JoinHostPort(addr, spectate.DefaultPort)
+		}
+		viewer, err := spectate.Watch(addr)
+		if err != nil {
+			log.Fatal(err)
+		}
+		term, err := openTerminal()
+		if err != nil {
+			log.Fatal(err)
+		}
+		err = watch(viewer, addr, readKeys(os.Stdin))
+		term.Close()
+		viewer.Close()
+		if err != nil {
+			fmt.Println(err)
+		}
+		return
+	}
+	if *showScores {
+		for i, mode := range engine.Modes() {
+			if i > 0 {
+				fmt.Println()
+			}
+			printLeaderboard(os.Stdout, scores, mode.Name, -1)
+		}
+		return
+	}
 
-	for !game.IsGameOver() {
-        game.GameTick()
-        fmt.Print("Enter Command(a:left,d:right,s:down,l:rotate left,r:rotate right, x exit):")
-		text, _ := input.ReadString('\n')
+	s := session.New(rules, handling, scores, scoresPath)
+	s.Seed = seed
+	s.Record = *record != ""
+	if bot.Play {
+		if s.Bot, err = bot.Load(); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if *replayPath != "" {
+		replay, err := engine.LoadReplay(*replayPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		s.StartReplay(replay)
+	}
+	game := NewGame(s, *record)
+	if *publish != "" {
+		if game.publisher, err = spectate.Listen(*publish); err != nil {
+			log.Fatal(err)
+		}
+		defer game.publisher.Close()
+	}
+
+	term, err := openTerminal()
+	if err != nil {
+		log.Fatal(err)
+	}
+	game.Run(readKeys(os.Stdin))
+	term.Close()
 
-        switch text {
-            case "a\n":
-                game.MoveLeft()
-            case "d\n":
-                game.MoveRight()
-            case "s\n":
-                game.MoveDown()
-            case "l\n":
-                game.RotateLeft()
-			case "r\n":
-				game.RotateRight()
-			case "x\n":
-				os.Exit(0)
-         }
-	}
-    fmt.Println("Game Over! Your final score :", game.score)
-}
\ No newline at end of file
+	if s.Game == nil {
+		return
+	}
+	if s.Replaying() {
+		fmt.Println("Replay finished, score :", s.Game.Score())
+		return
+	}
+	game.saveReplay()
+	fmt.Println("Your last score :", s.Game.Score())
+	fmt.Println("Seed:", s.Game.Seed())
+}
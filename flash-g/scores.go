@@ -0,0 +1,27 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"tetris-game/highscore"
+)
+
+// printLeaderboard writes the table of mode, marking the entry at
+// highlight.
+func printLeaderboard(w io.Writer, table *highscore.Table, mode string, highlight int) {
+	entries := table.Top(mode)
+	fmt.Fprintf(w, "High scores: %s\n", mode)
+	fmt.Fprintf(w, "%3s %-12s %7s %5s %5s %9s  %s\n", "#", "Name", "Score", "Lines", "Level", "Time", "Date")
+	for i, e := range entries {
+		mark := " "
+		if i == highlight {
+			mark = ">"
+		}
+		fmt.Fprintf(w, "%s%2d %-12s %7d %5d %5d %9s  %s\n", mark, i+1, e.Name, e.Score, e.Lines, e.Level,
+			highscore.FormatDuration(e.Duration), e.Date.Format("2006-01-02"))
+	}
+	if len(entries) == 0 {
+		fmt.Fprintln(w, "   no games yet")
+	}
+}
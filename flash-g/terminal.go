@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"golang.org/x/term"
+)
+
+// ANSI control sequences used to redraw the screen in place.
+const (
+	cursorHome  = "\x1b[H"
+	clearScreen = "\x1b[2J"
+	clearLine   = "\x1b[K"
+	clearBelow  = "\x1b[J"
+	hideCursor  = "\x1b[?25l"
+	showCursor  = "\x1b[?25h"
+	resetColor  = "\x1b[0m"
+)
+
+// terminal puts stdin in raw mode so single key presses arrive without
+// Enter and are not echoed.
+type terminal struct {
+	fd    int
+	state *term.State
+}
+
+func openTerminal() (*terminal, error) {
+	fd := int(os.Stdin.Fd())
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return nil, fmt.Errorf("flash-g needs an interactive terminal: %w", err)
+	}
+	fmt.Print(clearScreen + cursorHome + hideCursor)
+	return &terminal{fd: fd, state: state}, nil
+}
+
+// Close restores the terminal, leaving the last frame on screen.
+func (t *terminal) Close() error {
+	fmt.Print(resetColor + showCursor + "\r\n")
+	return term.Restore(t.fd, t.state)
+}
+
+// readKeys sends every key read from r on the returned channel, which is
+// closed when r fails. Arrow keys arrive as their escape sequences.
+func readKeys(r io.Reader) <-chan string {
+	keys := make(chan string)
+	go func() {
+		defer close(keys)
+		buf := make([]byte, 64)
+		for {
+			n, err := r.Read(buf)
+			if err != nil {
+				return
+			}
+			for _, key := range splitKeys(buf[:n]) {
+				keys <- key
+			}
+		}
+	}()
+	return keys
+}
+
+// splitKeys breaks one read into keys. Several keys may arrive together
+// when they repeat quickly.
+func splitKeys(b []byte) []string {
+	var keys []string
+	for len(b) > 0 {
+		n := 1
+		if b[0] == 0x1b && len(b) >= 3 && b[1] == '[' {
+			n = 3
+		}
+		keys = append(keys, string(b[:n]))
+		b = b[n:]
+	}
+	return keys
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"tetris-game/engine"
+	"tetris-game/spectate"
+)
+
+// watch shows the game published by viewer until a quit key is pressed.
+// It returns the error that ended the stream, if it ended first.
+func watch(viewer *spectate.Viewer, addr string, keys <-chan string) error {
+	ticker := time.NewTicker(time.Second / engine.TicksPerSecond)
+	defer ticker.Stop()
+	var scr screen
+	var announcement string
+	for {
+		select {
+		case key, ok := <-keys:
+			if !ok || key == ctrlC || quitKeys[key] {
+				return nil
+			}
+		case <-ticker.C:
+			if err := viewer.Err(); err != nil {
+				return err
+			}
+			var labels []string
+			for _, e := range viewer.Events() {
+				if label := e.Label(); label != "" {
+					labels = append(labels, label)
+				}
+			}
+			if len(labels) > 0 {
+				announcement = strings.Join(labels, ", ")
+			}
+			scr.draw(watchLines(viewer, addr, announcement))
+		}
+	}
+}
+
+// watchLines renders the state of the watched game the way Drawboard
+// renders a live one.
+func watchLines(viewer *spectate.Viewer, addr, announcement string) []string {
+	state, frame, ok := viewer.State()
+	lines := []string{"Watching " + addr + "  x quit"}
+	if !ok {
+		return append(lines, "", "Waiting for the game...")
+	}
+	lines = append(lines,
+		fmt.Sprintf("Score: %d  Level: %d  Lines: %d  Time: %s", state.Score, state.Level, state.Lines, ticksTime(frame)),
+		announcement)
+	board := state.DecodeBoard()
+	piece := state.Piece
+	ghost := board.Drop(piece)
+	lines = append(lines, boardRows(board, &piece, &ghost, panelLines(state.Hold, state.CanHold, state.Next))...)
+	if state.Over {
+		lines = append(lines, "GAME OVER")
+	}
+	return lines
+}
+
+func ticksTime(frame int) string {
+	d := time.Duration(frame) * time.Second / engine.TicksPerSecond
+	return fmt.Sprintf("%d:%02d", int(d.Minutes()), int(d.Seconds())%60)
+}
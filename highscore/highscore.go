@@ -0,0 +1,203 @@
+// Package highscore keeps the best games of each mode in a JSON file in
+// the user's config directory.
+package highscore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"tetris-game/engine"
+)
+
+// DefaultSize is how many entries a Table keeps per mode.
+const DefaultSize = 10
+
+// MaxNameLength caps the names entered on game over.
+const MaxNameLength = 12
+
+// DefaultName is used when a name is left empty.
+const DefaultName = "Player"
+
+// Entry is one finished game.
+type Entry struct {
+	Name     string        `json:"name"`
+	Score    int           `json:"score"`
+	Lines    int           `json:"lines"`
+	Level    int           `json:"level"`
+	Duration time.Duration `json:"duration"`
+	Seed     int64         `json:"seed"`
+	Date     time.Time     `json:"date"`
+
+	Pieces int `json:"pieces,omitempty"`
+	// Splits are the times every engine.SplitLines lines were reached.
+	Splits []time.Duration `json:"splits,omitempty"`
+	// Completed is set when the game reached its mode's goal.
+	Completed bool `json:"completed,omitempty"`
+}
+
+// Table holds the top entries of every mode, best first.
+type Table struct {
+	Size  int                `json:"size"`
+	Modes map[string][]Entry `json:"modes"`
+}
+
+func NewTable() *Table {
+	return &Table{Size: DefaultSize, Modes: make(map[string][]Entry)}
+}
+
+// DefaultPath returns where the table is stored for the current user.
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "tetris-game", "highscores.json"), nil
+}
+
+// Load reads the table at path. A missing file is an empty table.
+func Load(path string) (*Table, error) {
+	table := NewTable()
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return table, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, table); err != nil {
+		return nil, fmt.Errorf("highscore: reading %s: %w", path, err)
+	}
+	if table.Size <= 0 {
+		table.Size = DefaultSize
+	}
+	if table.Modes == nil {
+		table.Modes = make(map[string][]Entry)
+	}
+	return table, nil
+}
+
+// LoadDefault loads the table at DefaultPath and returns the path with it.
+func LoadDefault() (*Table, string, error) {
+	path, err := DefaultPath()
+	if err != nil {
+		return NewTable(), "", err
+	}
+	table, err := Load(path)
+	if err != nil {
+		return NewTable(), path, err
+	}
+	return table, path, nil
+}
+
+// Save writes the table to path, creating its directory. The file is
+// replaced in one step so a crash never leaves half a table.
+func (t *Table) Save(path string) error {
+	data, err := json.MarshalIndent(t, "", "\t")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Top returns the entries of mode, best first.
+func (t *Table) Top(mode string) []Entry {
+	return t.Modes[mode]
+}
+
+// Best returns the best entry of mode, the player's personal best.
+func (t *Table) Best(mode string) (Entry, bool) {
+	entries := t.Modes[mode]
+	if len(entries) == 0 {
+		return Entry{}, false
+	}
+	return entries[0], true
+}
+
+// Qualifies reports whether e would make the table for mode. Modes
+// ranked by time only take completed games.
+func (t *Table) Qualifies(mode string, e Entry) bool {
+	return t.rank(mode, e) < t.Size
+}
+
+// Add inserts e into the table for mode and returns its rank, counting
+// from zero, or -1 if it did not make the table.
+func (t *Table) Add(mode string, e Entry) int {
+	rank := t.rank(mode, e)
+	if rank >= t.Size {
+		return -1
+	}
+	entries := t.Modes[mode]
+	entries = append(entries, Entry{})
+	copy(entries[rank+1:], entries[rank:])
+	entries[rank] = e
+	if len(entries) > t.Size {
+		entries = entries[:t.Size]
+	}
+	t.Modes[mode] = entries
+	return rank
+}
+
+// rank is where e goes in mode's entries, after every better entry, or
+// t.Size if it cannot be ranked.
+func (t *Table) rank(mode string, e Entry) int {
+	m, _ := engine.LookupMode(mode)
+	if m.RankByTime && !e.Completed {
+		return t.Size
+	}
+	entries := t.Modes[mode]
+	return sort.Search(len(entries), func(i int) bool {
+		return better(m, e, entries[i])
+	})
+}
+
+// better orders entries by score, ties going to the faster game, or by
+// time first in modes ranked by time.
+func better(m engine.Mode, a, b Entry) bool {
+	if m.RankByTime && a.Duration != b.Duration {
+		return a.Duration < b.Duration
+	}
+	if a.Score != b.Score {
+		return a.Score > b.Score
+	}
+	return a.Duration < b.Duration
+}
+
+// FormatDuration prints d as minutes, seconds and milliseconds, e.g.
+// "1:23.456".
+func FormatDuration(d time.Duration) string {
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%d:%02d.%03d", ms/60000, ms/1000%60, ms%1000)
+}
+
+// NewEntry records the result of game under name, dated now.
+func NewEntry(name string, game *engine.Game) Entry {
+	if name == "" {
+		name = DefaultName
+	}
+	return Entry{
+		Name:     name,
+		Score:    game.Score(),
+		Lines:    game.Lines(),
+		Level:    game.Level(),
+		Duration: game.Elapsed(),
+		Seed:     game.Seed(),
+		Date:     time.Now(),
+
+		Pieces:    game.Pieces(),
+		Splits:    game.Splits(),
+		Completed: game.Completed(),
+	}
+}
@@ -0,0 +1,175 @@
+package highscore
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"tetris-game/engine"
+)
+
+func names(entries []Entry) []string {
+	var out []string
+	for _, e := range entries {
+		out = append(out, e.Name)
+	}
+	return out
+}
+
+// table returns a table of size holding entries for mode, in order.
+func table(size int, mode string, entries ...Entry) *Table {
+	t := NewTable()
+	t.Size = size
+	if entries != nil {
+		t.Modes[mode] = entries
+	}
+	return t
+}
+
+func TestAdd(t *testing.T) {
+	sec := time.Second
+	scores := []Entry{
+		{Name: "a", Score: 300, Duration: 60 * sec},
+		{Name: "b", Score: 200, Duration: 60 * sec},
+		{Name: "c", Score: 100, Duration: 60 * sec},
+	}
+	times := []Entry{
+		{Name: "a", Score: 100, Duration: 50 * sec, Completed: true},
+		{Name: "b", Score: 300, Duration: 60 * sec, Completed: true},
+		{Name: "c", Score: 200, Duration: 70 * sec, Completed: true},
+	}
+	tests := []struct {
+		name     string
+		mode     string
+		size     int
+		entries  []Entry
+		add      Entry
+		wantRank int
+		want     []string
+	}{
+		{"first entry", engine.ModeMarathon, 3, nil,
+			Entry{Name: "x", Score: 10}, 0, []string{"x"}},
+		{"best score", engine.ModeMarathon, 4, scores,
+			Entry{Name: "x", Score: 400}, 0, []string{"x", "a", "b", "c"}},
+		{"middle score", engine.ModeMarathon, 4, scores,
+			Entry{Name: "x", Score: 250}, 1, []string{"a", "x", "b", "c"}},
+		{"tie goes to the faster game", engine.ModeMarathon, 4, scores,
+			Entry{Name: "x", Score: 200, Duration: 30 * sec}, 1, []string{"a", "x", "b", "c"}},
+		{"tie with an equal game goes after it", engine.ModeMarathon, 4, scores,
+			Entry{Name: "x", Score: 200, Duration: 60 * sec}, 2, []string{"a", "b", "x", "c"}},
+		{"truncated to size", engine.ModeMarathon, 3, scores,
+			Entry{Name: "x", Score: 250}, 1, []string{"a", "x", "b"}},
+		{"below a full table", engine.ModeMarathon, 3, scores,
+			Entry{Name: "x", Score: 50}, -1, []string{"a", "b", "c"}},
+		{"sprint by time", engine.ModeSprint, 4, times,
+			Entry{Name: "x", Score: 0, Duration: 55 * sec, Completed: true}, 1, []string{"a", "x", "b", "c"}},
+		{"dig by time", engine.ModeDig, 4, times,
+			Entry{Name: "x", Score: 1000, Duration: 65 * sec, Completed: true}, 2, []string{"a", "b", "x", "c"}},
+		{"time tie goes to the higher score", engine.ModeSprint, 4, times,
+			Entry{Name: "x", Score: 400, Duration: 60 * sec, Completed: true}, 1, []string{"a", "x", "b", "c"}},
+		{"uncompleted sprint", engine.ModeSprint, 4, times,
+			Entry{Name: "x", Score: 1000, Duration: 10 * sec}, -1, []string{"a", "b", "c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := table(tt.size, tt.mode, slices.Clone(tt.entries)...)
+			if got := table.Add(tt.mode, tt.add); got != tt.wantRank {
+				t.Errorf("rank %d, want %d", got, tt.wantRank)
+			}
+			if got := names(table.Top(tt.mode)); !slices.Equal(got, tt.want) {
+				t.Errorf("table %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQualifies(t *testing.T) {
+	full := table(1, engine.ModeMarathon, Entry{Name: "a", Score: 100})
+	tests := []struct {
+		name  string
+		table *Table
+		mode  string
+		e     Entry
+		want  bool
+	}{
+		{"empty table", NewTable(), engine.ModeMarathon, Entry{}, true},
+		{"better than a full table", full, engine.ModeMarathon, Entry{Score: 101}, true},
+		{"worse than a full table", full, engine.ModeMarathon, Entry{Score: 99}, false},
+		{"other mode", full, engine.ModeUltra, Entry{Score: 1}, true},
+		{"completed sprint", NewTable(), engine.ModeSprint, Entry{Completed: true}, true},
+		{"uncompleted sprint", NewTable(), engine.ModeSprint, Entry{Score: 1000}, false},
+		{"uncompleted dig", NewTable(), engine.ModeDig, Entry{Score: 1000}, false},
+		{"uncompleted marathon", NewTable(), engine.ModeMarathon, Entry{Score: 1000}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.table.Qualifies(tt.mode, tt.e); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBest(t *testing.T) {
+	table := NewTable()
+	if _, ok := table.Best(engine.ModeSprint); ok {
+		t.Error("best of an empty mode")
+	}
+	table.Add(engine.ModeMarathon, Entry{Name: "b", Score: 1})
+	table.Add(engine.ModeMarathon, Entry{Name: "a", Score: 2})
+	if best, ok := table.Best(engine.ModeMarathon); !ok || best.Name != "a" {
+		t.Errorf("best %q, %v, want a", best.Name, ok)
+	}
+}
+
+func TestLoadMissing(t *testing.T) {
+	table, err := Load(filepath.Join(t.TempDir(), "highscores.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if table.Size != DefaultSize || len(table.Modes) != 0 {
+		t.Errorf("got size %d with %d modes, want an empty table", table.Size, len(table.Modes))
+	}
+	table.Add(engine.ModeMarathon, Entry{Name: "a"})
+}
+
+func TestSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dir", "highscores.json")
+	saved := table(5, engine.ModeSprint, Entry{
+		Name:      "a",
+		Score:     100,
+		Duration:  90 * time.Second,
+		Splits:    []time.Duration{time.Second, 2 * time.Second},
+		Completed: true,
+	})
+	if err := saved.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, want := loaded.Top(engine.ModeSprint), saved.Top(engine.ModeSprint)
+	if loaded.Size != 5 || len(got) != 1 || got[0].Name != want[0].Name ||
+		got[0].Duration != want[0].Duration || !slices.Equal(got[0].Splits, want[0].Splits) || !got[0].Completed {
+		t.Errorf("loaded %+v, want %+v", loaded, saved)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "highscores.json")
+	if err := os.WriteFile(path, []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("loaded a broken file")
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	if got := FormatDuration(83*time.Second + 456*time.Millisecond); got != "1:23.456" {
+		t.Errorf("got %q", got)
+	}
+}
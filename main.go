@@ -1,225 +1,218 @@
 package main
 
 import (
-	"image/color"
+	"flag"
+	"fmt"
 	"log"
-	"math/rand"
-	"time"
+	"os"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
-	"github.com/hajimehoshi/ebiten/v2/inpututil"
+
+	"tetris-game/cli"
+	"tetris-game/engine"
+	"tetris-game/highscore"
+	"tetris-game/render"
+	"tetris-game/session"
+	"tetris-game/spectate"
 )
 
 const (
 	screenWidth  = 320
 	screenHeight = 480
 	blockSize    = 20
-	boardWidth   = 10
-	boardHeight  = 20
+	previewSize  = 15
+
+	// The side panel to the right of the board.
+	panelX = engine.BoardWidth*blockSize + 10
 )
 
 type Game struct {
-	board        [][]int
-	currentPiece *Piece
-	nextPiece    *Piece
-	score        int
-	gameOver     bool
-	lastUpdate   time.Time
-}
+	session   *session.Session
+	announcer render.Announcer
 
-type Piece struct {
-	shape [][]int
-	x, y  int
+	// Live games are recorded and written to recordPath when they end.
+	recordPath string
+	// publisher streams every tick to viewers, if set.
+	publisher *spectate.Publisher
 }
 
-var pieces = [][][]int{
-	{{1, 1, 1, 1}}, // I
-	{{1, 1}, {1, 1}}, // O
-	{{1, 1, 1}, {0, 1, 0}}, // T
-	{{1, 1, 0}, {0, 1, 1}}, // S
-	{{0, 1, 1}, {1, 1, 0}}, // Z
-	{{1, 1, 1}, {1, 0, 0}}, // L
-	{{1, 1, 1}, {0, 0, 1}}, // J
+func NewGame(s *session.Session) *Game {
+	return &Game{session: s}
 }
 
-func NewGame() *Game {
-	g := &Game{
-		board: make([][]int, boardHeight),
+func (g *Game) Update() error {
+	state := g.session.State
+	events := g.session.Update(render.SessionInput(readInput()))
+	g.announcer.Update(events)
+	if g.publisher != nil && g.session.Game != nil {
+		g.publisher.Publish(g.session.Game, events)
 	}
-	for i := range g.board {
-		g.board[i] = make([]int, boardWidth)
+	if state == session.Playing && g.session.State == session.GameOver {
+		g.saveReplay()
 	}
-	g.spawnPiece()
-	g.lastUpdate = time.Now()
-	return g
+	return nil
 }
 
-func (g *Game) spawnPiece() {
-	g.currentPiece = g.nextPiece
-	if g.currentPiece == nil {
-		g.currentPiece = &Piece{
-			shape: pieces[rand.Intn(len(pieces))],
-			x:     boardWidth/2 - 1,
-			y:     0,
-		}
+// readInput returns the buttons held down on the keyboard.
+func readInput() engine.Input {
+	var in engine.Input
+	if ebiten.IsKeyPressed(ebiten.KeyLeft) {
+		in |= engine.InputLeft
 	}
-	g.nextPiece = &Piece{
-		shape: pieces[rand.Intn(len(pieces))],
-		x:     boardWidth/2 - 1,
-		y:     0,
+	if ebiten.IsKeyPressed(ebiten.KeyRight) {
+		in |= engine.InputRight
 	}
-}
-
-func (g *Game) Update() error {
-	if g.gameOver {
-		return nil
+	if ebiten.IsKeyPressed(ebiten.KeyDown) {
+		in |= engine.InputSoftDrop
 	}
-
-	if inpututil.IsKeyJustPressed(ebiten.KeyLeft) {
-		g.movePiece(-1, 0)
+	if ebiten.IsKeyPressed(ebiten.KeyUp) || ebiten.IsKeyPressed(ebiten.KeyX) {
+		in |= engine.InputRotateRight
 	}
-	if inpututil.IsKeyJustPressed(ebiten.KeyRight) {
-		g.movePiece(1, 0)
+	if ebiten.IsKeyPressed(ebiten.KeyZ) {
+		in |= engine.InputRotateLeft
 	}
-	if inpututil.IsKeyJustPressed(ebiten.KeyDown) {
-		g.movePiece(0, 1)
+	if ebiten.IsKeyPressed(ebiten.KeyC) || ebiten.IsKeyPressed(ebiten.KeyShift) {
+		in |= engine.InputHold
 	}
-	if inpututil.IsKeyJustPressed(ebiten.KeyUp) {
-		g.rotatePiece()
+	if ebiten.IsKeyPressed(ebiten.KeySpace) {
+		in |= engine.InputHardDrop
 	}
+	return in
+}
 
-	if time.Since(g.lastUpdate) > 500*time.Millisecond {
-		if !g.movePiece(0, 1) {
-			g.placePiece()
-			g.clearLines()
-			g.spawnPiece()
-			if g.checkCollision(0, 0) {
-				g.gameOver = true
-			}
-		}
-		g.lastUpdate = time.Now()
+// saveReplay writes the last game recorded to recordPath.
+func (g *Game) saveReplay() {
+	if g.session.Recorder == nil || g.recordPath == "" {
+		return
+	}
+	if err := g.session.Recorder.Replay().Save(g.recordPath); err != nil {
+		log.Print(err)
 	}
-
-	return nil
 }
 
-func (g *Game) movePiece(dx, dy int) bool {
-	if !g.checkCollision(dx, dy) {
-		g.currentPiece.x += dx
-		g.currentPiece.y += dy
-		return true
+func (g *Game) Draw(screen *ebiten.Image) {
+	if g.session.BoardVisible() {
+		g.drawGame(screen, g.session.Game)
 	}
-	return false
+	render.Overlay(screen, g.session, 20, 120)
 }
 
-func (g *Game) rotatePiece() {
-	oldShape := g.currentPiece.shape
-	newShape := make([][]int, len(oldShape[0]))
-	for i := range newShape {
-		newShape[i] = make([]int, len(oldShape))
-	}
-	for i := range oldShape {
-		for j := range oldShape[i] {
-			newShape[j][len(oldShape)-1-i] = oldShape[i][j]
-		}
-	}
-	g.currentPiece.shape = newShape
-	if g.checkCollision(0, 0) {
-		g.currentPiece.shape = oldShape
+func (g *Game) drawGame(screen *ebiten.Image, game *engine.Game) {
+	grid := render.Grid{Size: blockSize}
+
+	// Draw the board, the ghost and the current piece
+	grid.Board(screen, game.Board)
+	grid.Current(screen, game)
+
+	// Draw the side panel
+	render.Hold(screen, game, panelX, 0, previewSize)
+	render.Next(screen, game, panelX, 70, previewSize, 45)
+
+	render.Stats(screen, g.session, panelX, 330)
+	g.announcer.Draw(screen, 0, engine.BoardHeight*blockSize+4)
+
+	// Draw the score
+	ebitenutil.DebugPrint(screen, fmt.Sprintf("Score: %d\nLevel: %d", game.Score(), game.Level()))
+
+	if g.session.Replaying() {
+		ebitenutil.DebugPrintAt(screen, "Replay", 0, 32)
 	}
 }
 
-func (g *Game) checkCollision(dx, dy int) bool {
-	for i := range g.currentPiece.shape {
-		for j := range g.currentPiece.shape[i] {
-			if g.currentPiece.shape[i][j] != 0 {
-				x := g.currentPiece.x + j + dx
-				y := g.currentPiece.y + i + dy
-				if x < 0 || x >= boardWidth || y >= boardHeight || (y >= 0 && g.board[y][x] != 0) {
-					return true
-				}
-			}
-		}
-	}
-	return false
+func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
+	return screenWidth, screenHeight
 }
 
-func (g *Game) placePiece() {
-	for i := range g.currentPiece.shape {
-		for j := range g.currentPiece.shape[i] {
-			if g.currentPiece.shape[i][j] != 0 {
-				x := g.currentPiece.x + j
-				y := g.currentPiece.y + i
-				g.board[y][x] = 1
-			}
+func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "serve":
+			cli.Serve(os.Args[2:])
+			return
+		case "join":
+			join(os.Args[2:])
+			return
+		case "watch":
+			watch(os.Args[2:])
+			return
+		case "train":
+			cli.Train(os.Args[2:])
+			return
 		}
 	}
-}
 
-func (g *Game) clearLines() {
-	linesCleared := 0
-	for y := boardHeight - 1; y >= 0; y-- {
-		full := true
-		for x := 0; x < boardWidth; x++ {
-			if g.board[y][x] == 0 {
-				full = false
-				break
-			}
-		}
-		if full {
-			g.board = append(g.board[:y], g.board[y+1:]...)
-			g.board = append([][]int{make([]int, boardWidth)}, g.board...)
-			linesCleared++
-		}
+	rules := engine.DefaultRules()
+	var seed int64
+	cli.RulesFlags(flag.CommandLine, &rules, &seed)
+	record := flag.String("record", "", "write a replay of the game to this file")
+	replayPath := flag.String("replay", "", "play back a replay file instead of a live game")
+	versusMode := flag.Bool("versus", false, "play a two-player match on one keyboard")
+	publish := flag.String("publish", "", "stream the game to viewers on this TCP address, e.g. :"+spectate.DefaultPort)
+	handling := engine.DefaultHandling()
+	cli.HandlingFlags(flag.CommandLine, &handling)
+	var bot cli.Bot
+	cli.BotFlags(flag.CommandLine, &bot)
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: tetris [flags]\n       tetris serve [flags]\n       tetris join [flags] host[:port]\n       tetris watch host[:port]\n       tetris train [flags]\n\nServers and training also run headless with tetris-headless.")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() > 0 {
+		fmt.Fprintf(flag.CommandLine.Output(), "unexpected argument %q\n", flag.Arg(0))
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := rules.Validate(); err != nil {
+		log.Fatal(err)
+	}
+	if err := handling.Validate(); err != nil {
+		log.Fatal(err)
 	}
-	g.score += linesCleared * 100
-}
 
-func (g *Game) Draw(screen *ebiten.Image) {
-	white := color.RGBA{255, 255, 255, 255}
-	red := color.RGBA{255, 0, 0, 255}
-
-	// Draw the board
-	for y := 0; y < boardHeight; y++ {
-		for x := 0; x < boardWidth; x++ {
-			if g.board[y][x] != 0 {
-				ebitenutil.DrawRect(screen, float64(x*blockSize), float64(y*blockSize), blockSize, blockSize, white)
-			}
+	ebiten.SetTPS(engine.TicksPerSecond)
+	if *versusMode {
+		ebiten.SetWindowSize(versusWidth, versusHeight)
+		ebiten.SetWindowTitle("Tetris - Versus")
+		if err := ebiten.RunGame(NewVersus(seed, rules, handling)); err != nil {
+			log.Fatal(err)
 		}
+		return
 	}
 
-	// Draw the current piece
-	if g.currentPiece != nil {
-		for i := range g.currentPiece.shape {
-			for j := range g.currentPiece.shape[i] {
-				if g.currentPiece.shape[i][j] != 0 {
-					x := g.currentPiece.x + j
-					y := g.currentPiece.y + i
-					ebitenutil.DrawRect(screen, float64(x*blockSize), float64(y*blockSize), blockSize, blockSize, red)
-				}
-			}
+	scores, scoresPath, err := highscore.LoadDefault()
+	if err != nil {
+		log.Print(err)
+	}
+	s := session.New(rules, handling, scores, scoresPath)
+	s.Seed = seed
+	s.Record = *record != ""
+	if bot.Play {
+		if s.Bot, err = bot.Load(); err != nil {
+			log.Fatal(err)
 		}
 	}
-
-	// Draw the score
-	ebitenutil.DebugPrint(screen, "Score: "+string(g.score))
-
-	// Draw game over message
-	if g.gameOver {
-		ebitenutil.DebugPrint(screen, "Game Over")
+	if *replayPath != "" {
+		replay, err := engine.LoadReplay(*replayPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		s.StartReplay(replay)
+	}
+	game := NewGame(s)
+	game.recordPath = *record
+	if *publish != "" {
+		if game.publisher, err = spectate.Listen(*publish); err != nil {
+			log.Fatal(err)
+		}
+		defer game.publisher.Close()
 	}
-}
-
-func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
-	return screenWidth, screenHeight
-}
 
-func main() {
-	rand.Seed(time.Now().UnixNano())
 	ebiten.SetWindowSize(screenWidth, screenHeight)
 	ebiten.SetWindowTitle("Tetris")
-	if err := ebiten.RunGame(NewGame()); err != nil {
+	if err := ebiten.RunGame(game); err != nil {
 		log.Fatal(err)
 	}
-}
\ No newline at end of file
+	game.saveReplay()
+}
@@ -0,0 +1,202 @@
+package netplay
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"tetris-game/engine"
+)
+
+// ClientState is how far a client is into its match.
+type ClientState int
+
+const (
+	Waiting  ClientState = iota // for an opponent to join
+	Playing                     // the match
+	Finished                    // the match has a winner
+)
+
+// Opponent is what a client knows of the other player's game: the board
+// after their last lock and the garbage sent so far.
+type Opponent struct {
+	Name  string
+	Board engine.Board
+	Score int
+	Lines int
+}
+
+// Client plays one match on a server. Frontends call Update once per
+// tick and draw Game and Opponent.
+type Client struct {
+	State    ClientState
+	Game     *engine.Game
+	Opponent Opponent
+	// Player is this client's index in the match.
+	Player int
+	Winner int
+
+	conn     *Conn
+	messages chan Message
+	err      error
+	handling engine.Handling
+	held     engine.Input
+	overSent bool
+}
+
+// dialTimeout bounds how long Join waits for the server to answer.
+const dialTimeout = 5 * time.Second
+
+// Join connects to the server at addr as name and waits for its welcome.
+// The match starts from Update once the server has paired the client.
+func Join(addr, name string, handling engine.Handling) (*Client, error) {
+	conn, err := net.DialTimeout("tcp", addr, dialTimeout)
+	if err != nil {
+		return nil, err
+	}
+	c := &Client{
+		conn:     NewConn(conn),
+		messages: make(chan Message, 64),
+		handling: handling,
+	}
+	hello := Message{Type: TypeHello, Version: ProtocolVersion, Name: name, Handling: &handling}
+	if err := c.conn.Send(hello); err != nil {
+		c.conn.Close()
+		return nil, err
+	}
+	welcome, err := c.conn.Receive()
+	if err != nil {
+		c.conn.Close()
+		return nil, err
+	}
+	if welcome.Type != TypeWelcome || welcome.Version != ProtocolVersion {
+		c.conn.Close()
+		return nil, fmt.Errorf("netplay: server speaks %s version %d, want %d", welcome.Type, welcome.Version, ProtocolVersion)
+	}
+	go c.read()
+	return c, nil
+}
+
+// read passes the server's messages to Update until the connection ends.
+func (c *Client) read() {
+	defer close(c.messages)
+	for {
+		m, err := c.conn.Receive()
+		if err != nil {
+			c.messages <- Message{Type: TypeError, Text: err.Error()}
+			return
+		}
+		c.messages <- m
+	}
+}
+
+// Err returns why the connection ended early, if it did.
+func (c *Client) Err() error {
+	return c.err
+}
+
+// Close leaves the match.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Update handles the messages from the server and, while playing, ticks
+// the game with the buttons in held, reports it to the server and returns
+// the events of the tick.
+func (c *Client) Update(held engine.Input) []engine.Event {
+	c.receive()
+	if c.State != Playing {
+		return nil
+	}
+	frame := c.Game.Frame()
+	if held != c.held {
+		c.held = held
+		c.send(Message{Type: TypeInput, Frame: frame, Input: held})
+	}
+	c.Game.Tick(held)
+	events := c.Game.Events()
+	for _, e := range events {
+		if e.Kind != engine.EventLock {
+			continue
+		}
+		c.send(Message{Type: TypeLock, Frame: frame, Board: engine.EncodeBoard(c.Game.Board)})
+	}
+	if c.Game.IsGameOver() && !c.overSent {
+		c.overSent = true
+		c.send(Message{Type: TypeOver, Frame: frame})
+	}
+	return events
+}
+
+func (c *Client) receive() {
+	for {
+		select {
+		case m, ok := <-c.messages:
+			if !ok {
+				return
+			}
+			c.handle(m)
+		default:
+			return
+		}
+	}
+}
+
+func (c *Client) handle(m Message) {
+	switch m.Type {
+	case TypeStart:
+		if m.Rules == nil {
+			c.fail(fmt.Errorf("netplay: start without rules"))
+			return
+		}
+		if err := m.Rules.Validate(); err != nil {
+			c.fail(err)
+			return
+		}
+		c.Player = m.Player
+		c.Game = engine.NewGame(m.Seed, *m.Rules)
+		c.Game.SetHandling(c.handling)
+		c.Opponent = Opponent{Name: m.Name, Board: engine.NewBoard()}
+		c.State = Playing
+	case TypeLock:
+		c.Opponent.Board = engine.DecodeBoard(m.Board)
+		c.Opponent.Score = m.Score
+		c.Opponent.Lines = m.Lines
+	case TypeGarbage:
+		if c.State == Playing {
+			c.Game.ReceiveGarbage(m.Lines)
+			c.send(Message{Type: TypeGarbage, Frame: c.Game.Frame(), Lines: m.Lines})
+		}
+	case TypeResult:
+		if m.Winner != nil {
+			c.Winner = *m.Winner
+		}
+		c.State = Finished
+		c.conn.Close()
+	case TypeError:
+		if c.State != Finished {
+			c.fail(fmt.Errorf("%s", m.Text))
+		}
+	}
+}
+
+// send reports to the server; a failure ends the match.
+func (c *Client) send(m Message) {
+	if err := c.conn.Send(m); err != nil {
+		c.fail(err)
+	}
+}
+
+func (c *Client) fail(err error) {
+	if c.err == nil {
+		c.err = err
+	}
+	c.State = Finished
+	c.Winner = -1
+	c.conn.Close()
+}
+
+// Won reports whether this client won a finished match.
+func (c *Client) Won() bool {
+	return c.State == Finished && c.err == nil && c.Winner == c.Player
+}
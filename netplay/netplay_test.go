@@ -0,0 +1,222 @@
+package netplay
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"tetris-game/engine"
+)
+
+// serve starts a server on a free local port and returns its address and
+// a channel of what it logs.
+func serve(t *testing.T) (string, <-chan string) {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	logs := make(chan string, 64)
+	rules := engine.DefaultRules()
+	rules.Randomizer = engine.RandomizerSequence
+	rules.Sequence = []engine.TetrominoType{engine.I}
+	server := &Server{Rules: rules, Seed: 1, Logf: func(format string, args ...any) {
+		select {
+		case logs <- fmt.Sprintf(format, args...):
+		default:
+		}
+	}}
+	go server.Serve(l)
+	return l.Addr().String(), logs
+}
+
+func join(t *testing.T, addr, name string) *Client {
+	t.Helper()
+	c, err := Join(addr, name, engine.DefaultHandling())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+// stand stacks vertical I pieces left to right, tapping a button every
+// other tick, so the tenth piece clears four rows.
+func stand(g *engine.Game) engine.Input {
+	if g.Frame()%2 == 1 {
+		return 0
+	}
+	t := g.CurrentTetromino
+	column := g.Pieces() % engine.BoardWidth
+	switch {
+	case t.Rotation != engine.R90:
+		return engine.InputRotateRight
+	case t.X+2 > column:
+		return engine.InputLeft
+	case t.X+2 < column:
+		return engine.InputRight
+	}
+	return engine.InputHardDrop
+}
+
+func hasGarbage(b engine.Board) bool {
+	for _, row := range b {
+		for _, cell := range row {
+			if cell == engine.Garbage {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func TestMatch(t *testing.T) {
+	addr, _ := serve(t)
+	attacker := join(t, addr, "attacker")
+	defender := join(t, addr, "defender")
+
+	// The defender waits for garbage, then hard drops until it tops out.
+	attacked, sawLock := false, false
+	deadline := time.Now().Add(10 * time.Second)
+	for attacker.State != Finished || defender.State != Finished {
+		if time.Now().After(deadline) {
+			t.Fatal("match did not finish")
+		}
+		if attacker.State == Waiting || defender.State == Waiting {
+			attacker.Update(0)
+			defender.Update(0)
+			time.Sleep(time.Millisecond)
+			continue
+		}
+		if attacker.State == Playing {
+			attacker.Update(stand(attacker.Game))
+		}
+		var held engine.Input
+		if g := defender.Game; g.PendingGarbage() > 0 || hasGarbage(g.Board) {
+			attacked = true
+			if g.Frame()%2 == 0 {
+				held = engine.InputHardDrop
+			}
+		}
+		defender.Update(held)
+		if !defender.Opponent.Board.IsEmpty() {
+			sawLock = true
+		}
+		time.Sleep(50 * time.Microsecond)
+	}
+
+	if err := attacker.Err(); err != nil {
+		t.Errorf("attacker: %v", err)
+	}
+	if err := defender.Err(); err != nil {
+		t.Errorf("defender: %v", err)
+	}
+	if !attacked {
+		t.Error("defender never received garbage")
+	}
+	if !sawLock {
+		t.Error("defender never saw the attacker's board")
+	}
+	if !attacker.Won() || defender.Won() || defender.Winner != 0 {
+		t.Errorf("attacker won %v, defender won %v with winner %d; want the attacker to win",
+			attacker.Won(), defender.Won(), defender.Winner)
+	}
+}
+
+// A client that leaves while waiting must not hand the next one a win.
+func TestLeaveWhileWaiting(t *testing.T) {
+	addr, logs := serve(t)
+	gone := join(t, addr, "gone")
+	gone.Close()
+	for log := range logs {
+		if strings.Contains(log, "left while waiting") {
+			break
+		}
+	}
+
+	a := join(t, addr, "a")
+	b := join(t, addr, "b")
+	deadline := time.Now().Add(5 * time.Second)
+	for a.State == Waiting || b.State == Waiting {
+		if time.Now().After(deadline) {
+			t.Fatal("match did not start")
+		}
+		a.Update(0)
+		b.Update(0)
+		time.Sleep(time.Millisecond)
+	}
+	if a.State != Playing || b.State != Playing {
+		t.Errorf("states %v and %v, want both playing", a.State, b.State)
+	}
+	if a.Opponent.Name != "b" || b.Opponent.Name != "a" {
+		t.Errorf("opponents %q and %q, want b and a", a.Opponent.Name, b.Opponent.Name)
+	}
+}
+
+// Clients that report a game the server does not replay forfeit.
+func TestOutOfSync(t *testing.T) {
+	addr, _ := serve(t)
+	honest := join(t, addr, "honest")
+	cheat := join(t, addr, "cheat")
+	deadline := time.Now().Add(5 * time.Second)
+	for cheat.State == Waiting || honest.State == Waiting {
+		if time.Now().After(deadline) {
+			t.Fatal("match did not start")
+		}
+		honest.Update(0)
+		cheat.Update(0)
+		time.Sleep(time.Millisecond)
+	}
+	board := engine.NewBoard()
+	board[engine.BoardHeight-1][0] = engine.PieceCell(engine.I)
+	cheat.send(Message{Type: TypeLock, Frame: cheat.Game.Frame(), Board: engine.EncodeBoard(board)})
+	for honest.State != Finished {
+		if time.Now().After(deadline) {
+			t.Fatal("match did not finish")
+		}
+		honest.Update(0)
+		time.Sleep(time.Millisecond)
+	}
+	if !honest.Won() {
+		t.Errorf("honest client lost to a client out of sync")
+	}
+}
+
+// A client refuses a start with rules it cannot play instead of crashing.
+func TestBadRules(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		c := NewConn(conn)
+		defer c.Close()
+		c.Receive()
+		c.Send(Message{Type: TypeWelcome, Version: ProtocolVersion})
+		rules := engine.DefaultRules()
+		rules.NextCount = -1
+		c.Send(Message{Type: TypeStart, Seed: 1, Rules: &rules, Name: "bad"})
+		c.Receive()
+	}()
+
+	client := join(t, l.Addr().String(), "client")
+	deadline := time.Now().Add(5 * time.Second)
+	for client.State != Finished {
+		if time.Now().After(deadline) {
+			t.Fatal("client did not refuse the start")
+		}
+		client.Update(0)
+		time.Sleep(time.Millisecond)
+	}
+	if client.Err() == nil {
+		t.Error("client finished without an error")
+	}
+}
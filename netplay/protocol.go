@@ -0,0 +1,135 @@
+// Package netplay plays versus matches over TCP. A server pairs the
+// clients that join it; each client runs its own game and reports its
+// input to the server. The engine is deterministic, so the server replays
+// every game from those inputs and is the authority on the garbage each
+// player sends, the boards their opponents see and who wins.
+//
+// The protocol is JSON lines: every message is one JSON object on its own
+// line. A client opens with a hello carrying ProtocolVersion and its
+// handling, and the server answers with a welcome, or an error and a
+// closed connection if the versions differ. Once two clients are waiting
+// the server sends both a start with the seed and rules of the match.
+// During the match clients send input whenever their buttons change,
+// garbage when they take in lines the server sent, and lock and over as
+// checks that they agree with the server's replay. The server sends each
+// player the opponent's locks and the garbage they attack with, and ends
+// the match with a result to both. A client whose reports do not match
+// the replay forfeits.
+package netplay
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sync"
+
+	"tetris-game/engine"
+)
+
+// ProtocolVersion is sent in every hello and welcome. Peers with another
+// version are refused.
+const ProtocolVersion = 2
+
+// DefaultPort is the TCP port servers listen on unless told otherwise.
+const DefaultPort = "7777"
+
+// Message types.
+const (
+	TypeHello   = "hello"   // client: version, name, handling
+	TypeWelcome = "welcome" // server: version
+	TypeStart   = "start"   // server: player, seed, rules, opponent's name
+	TypeInput   = "input"   // client: frame, buttons held from that frame on
+	TypeLock    = "lock"    // client: frame, board; server: the opponent's frame, attack, board, score, lines
+	TypeOver    = "over"    // client: frame the game topped out
+	TypeGarbage = "garbage" // server: lines sent by the opponent; client: frame they were taken in, lines
+	TypeResult  = "result"  // server: winner
+	TypeError   = "error"   // either: text, then the connection closes
+)
+
+// Message is one line of the protocol. Type says which fields are set.
+type Message struct {
+	Type    string        `json:"type"`
+	Version int           `json:"version,omitempty"`
+	Name    string        `json:"name,omitempty"`
+	Player  int           `json:"player,omitempty"`
+	Seed    int64         `json:"seed,omitempty"`
+	Rules   *engine.Rules `json:"rules,omitempty"`
+	// Handling is the client's, which the server needs to replay its
+	// input.
+	Handling *engine.Handling `json:"handling,omitempty"`
+	Frame    int              `json:"frame,omitempty"`
+	Input    engine.Input     `json:"input,omitempty"`
+	Attack   int              `json:"attack,omitempty"`
+	Lines    int              `json:"lines,omitempty"`
+	Score    int              `json:"score,omitempty"`
+	// Board is the board after a lock, one string per row; see
+	// engine.EncodeBoard.
+	Board  []string `json:"board,omitempty"`
+	Winner *int     `json:"winner,omitempty"`
+	Text   string   `json:"text,omitempty"`
+}
+
+// Conn sends and receives messages over a network connection. Send is
+// safe to call from several goroutines.
+type Conn struct {
+	conn    net.Conn
+	scanner *bufio.Scanner
+
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// maxLine bounds a message, which is large enough for any board.
+const maxLine = 64 * 1024
+
+func NewConn(conn net.Conn) *Conn {
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 4096), maxLine)
+	return &Conn{conn: conn, scanner: scanner, enc: json.NewEncoder(conn)}
+}
+
+// Send writes m as one line.
+func (c *Conn) Send(m Message) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.enc.Encode(m)
+}
+
+// Receive reads the next message. An error message from the peer is
+// returned as an error.
+func (c *Conn) Receive() (Message, error) {
+	if !c.scanner.Scan() {
+		if err := c.scanner.Err(); err != nil {
+			return Message{}, err
+		}
+		return Message{}, fmt.Errorf("netplay: %s closed the connection", c.conn.RemoteAddr())
+	}
+	var m Message
+	if err := json.Unmarshal(c.scanner.Bytes(), &m); err != nil {
+		return Message{}, fmt.Errorf("netplay: bad message from %s: %w", c.conn.RemoteAddr(), err)
+	}
+	if m.Type == TypeError {
+		return m, fmt.Errorf("netplay: %s: %s", c.conn.RemoteAddr(), m.Text)
+	}
+	return m, nil
+}
+
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
+
+// closeWrite stops sending, leaving the connection open for reading.
+func (c *Conn) closeWrite() {
+	if tc, ok := c.conn.(interface{ CloseWrite() error }); ok {
+		tc.CloseWrite()
+		return
+	}
+	c.conn.Close()
+}
+
+// fail sends an error message and closes the connection.
+func (c *Conn) fail(format string, args ...any) {
+	c.Send(Message{Type: TypeError, Text: fmt.Sprintf(format, args...)})
+	c.Close()
+}
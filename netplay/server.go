@@ -0,0 +1,335 @@
+package netplay
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"slices"
+	"time"
+
+	"tetris-game/engine"
+)
+
+// Server pairs the clients that connect to it, in order of arrival, and
+// referees a match between each pair.
+type Server struct {
+	// Rules are played by every match.
+	Rules engine.Rules
+	// Seed is dealt to every match; zero picks a new one each time.
+	Seed int64
+	// Logf reports connections and results; nil uses log.Printf.
+	Logf func(format string, args ...any)
+}
+
+// ListenAndServe listens on the TCP address addr and serves matches until
+// listening fails.
+func (s *Server) ListenAndServe(addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer l.Close()
+	s.logf("listening on %s", l.Addr())
+	return s.Serve(l)
+}
+
+// Serve accepts clients on l until it is closed.
+func (s *Server) Serve(l net.Listener) error {
+	waiting := make(chan *player)
+	go s.pair(waiting)
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go s.greet(NewConn(conn), waiting)
+	}
+}
+
+func (s *Server) logf(format string, args ...any) {
+	if s.Logf != nil {
+		s.Logf(format, args...)
+		return
+	}
+	log.Printf(format, args...)
+}
+
+// player is a client that said hello. Its messages arrive on reports
+// until the connection fails, which is the last report.
+type player struct {
+	conn     *Conn
+	name     string
+	handling engine.Handling
+	reports  chan report
+	quit     chan struct{}
+}
+
+// report is a message a player sent, or the error that ended its
+// connection.
+type report struct {
+	msg Message
+	err error
+}
+
+// read passes the player's messages to reports until the connection
+// fails or the player leaves.
+func (p *player) read() {
+	for {
+		m, err := p.conn.Receive()
+		select {
+		case p.reports <- report{msg: m, err: err}:
+		case <-p.quit:
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// leave closes the connection and stops reading it.
+func (p *player) leave() {
+	close(p.quit)
+	p.conn.Close()
+}
+
+// lingerTimeout bounds how long the server waits at the end of a match
+// for a client to read the result and hang up.
+const lingerTimeout = time.Second
+
+// linger closes the sending side of the connection and waits for the
+// client to close its own before leaving. Closing outright would reset
+// a connection the client is still writing to and could lose the result.
+func (p *player) linger() {
+	p.conn.closeWrite()
+	timeout := time.After(lingerTimeout)
+	for {
+		select {
+		case r := <-p.reports:
+			if r.err == nil {
+				continue
+			}
+		case <-timeout:
+		}
+		p.leave()
+		return
+	}
+}
+
+// greet checks the hello of a new client and hands it to the pairing.
+func (s *Server) greet(c *Conn, waiting chan<- *player) {
+	hello, err := c.Receive()
+	if err != nil {
+		s.logf("%v", err)
+		c.Close()
+		return
+	}
+	if hello.Type != TypeHello || hello.Version != ProtocolVersion {
+		s.logf("refused %s: %s version %d", c.conn.RemoteAddr(), hello.Type, hello.Version)
+		c.fail("expected hello with protocol version %d", ProtocolVersion)
+		return
+	}
+	if hello.Handling == nil {
+		c.fail("hello without handling")
+		return
+	}
+	if err := hello.Handling.Validate(); err != nil {
+		c.fail("%v", err)
+		return
+	}
+	if err := c.Send(Message{Type: TypeWelcome, Version: ProtocolVersion}); err != nil {
+		c.Close()
+		return
+	}
+	s.logf("%s joined from %s", hello.Name, c.conn.RemoteAddr())
+	p := &player{
+		conn:     c,
+		name:     hello.Name,
+		handling: *hello.Handling,
+		reports:  make(chan report),
+		quit:     make(chan struct{}),
+	}
+	go p.read()
+	waiting <- p
+}
+
+// pair starts a match for every two players that arrive. A player who
+// leaves, or says anything, before an opponent arrives is dropped.
+func (s *Server) pair(waiting chan *player) {
+	var first *player
+	for {
+		if first == nil {
+			first = <-waiting
+			continue
+		}
+		select {
+		case p := <-waiting:
+			go s.play([2]*player{first, p}, waiting)
+			first = nil
+		case r := <-first.reports:
+			if r.err == nil {
+				first.conn.fail("unexpected %s before the match started", r.msg.Type)
+			}
+			s.logf("%s left while waiting", first.name)
+			first.leave()
+			first = nil
+		}
+	}
+}
+
+// maxAhead bounds how far one report may move a player's game, so that a
+// bogus frame cannot keep the server ticking.
+const maxAhead = 10 * 60 * engine.TicksPerSecond
+
+// referee replays one player's game from the inputs they report. It is
+// the authority on the garbage they send and on when they top out.
+type referee struct {
+	game *engine.Game
+	held engine.Input
+	// owed is the garbage sent to the player that they have not yet
+	// reported taking in.
+	owed int
+}
+
+// play referees one match: it starts both players, replays each of their
+// games from the inputs they report, sends every lock and its garbage to
+// the opponent and sends the result once one of them tops out or leaves.
+// If a player cannot be started, the other goes back to waiting.
+func (s *Server) play(players [2]*player, waiting chan<- *player) {
+	seed := s.Seed
+	if seed == 0 {
+		seed = engine.NewSeed()
+	}
+	rules := s.Rules
+	for i, p := range players {
+		start := Message{Type: TypeStart, Player: i, Seed: seed, Rules: &rules, Name: players[1-i].name}
+		if err := p.conn.Send(start); err != nil {
+			s.logf("%s left: %v", p.name, err)
+			p.leave()
+			if i == 0 {
+				waiting <- players[1]
+			} else {
+				players[0].conn.fail("%s left before the match started", p.name)
+				players[0].leave()
+			}
+			return
+		}
+	}
+	defer players[0].linger()
+	defer players[1].linger()
+	s.logf("match %s vs %s, seed %d", players[0].name, players[1].name, seed)
+
+	var refs [2]*referee
+	for i, p := range players {
+		game := engine.NewGame(seed, rules)
+		game.SetHandling(p.handling)
+		refs[i] = &referee{game: game}
+	}
+
+	for {
+		var from int
+		var r report
+		select {
+		case r = <-players[0].reports:
+			from = 0
+		case r = <-players[1].reports:
+			from = 1
+		}
+		if r.err != nil {
+			s.logf("%s left: %v", players[from].name, r.err)
+			s.finish(players, 1-from)
+			return
+		}
+		if err := s.apply(refs, players, from, r.msg); err != nil {
+			s.logf("%s: %v", players[from].name, err)
+			players[from].conn.fail("%v", err)
+			s.finish(players, 1-from)
+			return
+		}
+		if game := refs[from].game; game.IsGameOver() {
+			winner := 1 - from
+			if game.Completed() {
+				winner = from
+			}
+			s.finish(players, winner)
+			return
+		}
+	}
+}
+
+// apply applies a message from player from to the replay of their game.
+func (s *Server) apply(refs [2]*referee, players [2]*player, from int, m Message) error {
+	ref := refs[from]
+	switch m.Type {
+	case TypeInput:
+		if err := s.advance(refs, players, from, m.Frame); err != nil {
+			return err
+		}
+		ref.held = m.Input
+	case TypeGarbage:
+		if m.Lines <= 0 || m.Lines > ref.owed {
+			return fmt.Errorf("took in %d garbage lines but was sent %d", m.Lines, ref.owed)
+		}
+		if err := s.advance(refs, players, from, m.Frame); err != nil {
+			return err
+		}
+		ref.owed -= m.Lines
+		ref.game.ReceiveGarbage(m.Lines)
+	case TypeLock:
+		if err := s.advance(refs, players, from, m.Frame+1); err != nil {
+			return err
+		}
+		if !slices.Equal(m.Board, engine.EncodeBoard(ref.game.Board)) {
+			return fmt.Errorf("out of sync on frame %d", m.Frame)
+		}
+	case TypeOver:
+		if err := s.advance(refs, players, from, m.Frame+1); err != nil {
+			return err
+		}
+		if !ref.game.IsGameOver() {
+			return fmt.Errorf("out of sync: over on frame %d", m.Frame)
+		}
+	}
+	return nil
+}
+
+// advance ticks the game of player from up to frame with the buttons they
+// last reported. Each lock is sent to the opponent with the garbage it
+// attacks them with.
+func (s *Server) advance(refs [2]*referee, players [2]*player, from, frame int) error {
+	ref, opponent := refs[from], refs[1-from]
+	game := ref.game
+	if frame > game.Frame()+maxAhead {
+		return fmt.Errorf("frame %d too far ahead of %d", frame, game.Frame())
+	}
+	for game.Frame() < frame && !game.IsGameOver() {
+		tick := game.Frame()
+		game.Tick(ref.held)
+		for _, e := range game.Events() {
+			if e.Kind != engine.EventLock {
+				continue
+			}
+			players[1-from].conn.Send(Message{
+				Type:   TypeLock,
+				Frame:  tick,
+				Attack: e.Attack,
+				Board:  engine.EncodeBoard(game.Board),
+				Score:  game.Score(),
+				Lines:  game.Lines(),
+			})
+			if e.Attack > 0 {
+				opponent.owed += e.Attack
+				players[1-from].conn.Send(Message{Type: TypeGarbage, Lines: e.Attack})
+			}
+		}
+	}
+	return nil
+}
+
+// finish sends the winner to both players.
+func (s *Server) finish(players [2]*player, winner int) {
+	s.logf("%s wins", players[winner].name)
+	for _, p := range players {
+		p.conn.Send(Message{Type: TypeResult, Winner: &winner})
+	}
+}
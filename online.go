@@ -0,0 +1,134 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"os"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+
+	"tetris-game/cli"
+	"tetris-game/engine"
+	"tetris-game/highscore"
+	"tetris-game/netplay"
+	"tetris-game/render"
+)
+
+const (
+	// The opponent's board is drawn at this cell size right of the panel.
+	miniSize = 10
+	miniX    = versusBoard + engine.BoardWidth*blockSize + 90
+	onlineW  = miniX + engine.BoardWidth*miniSize + 10
+)
+
+// Online is a match against a player on another machine, through a
+// netplay server.
+type Online struct {
+	client    *netplay.Client
+	announcer render.Announcer
+	server    string
+}
+
+func (o *Online) Update() error {
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		return ebiten.Termination
+	}
+	o.announcer.Update(o.client.Update(readInput()))
+	return nil
+}
+
+func (o *Online) Draw(screen *ebiten.Image) {
+	c := o.client
+	if c.Game == nil {
+		msg := "Waiting for an opponent on " + o.server + "\n\nEsc: quit"
+		if c.Err() != nil {
+			msg = c.Err().Error() + "\n\nEsc: quit"
+		}
+		render.Message(screen, msg, 10, 40)
+		return
+	}
+	game := c.Game
+	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("You  Score: %d", game.Score()), versusBoard, 2)
+	render.GarbageMeter(screen, game, 0, versusTop, meterWidth, blockSize)
+	grid := render.Grid{X: versusBoard, Y: versusTop, Size: blockSize}
+	grid.Board(screen, game.Board)
+	if c.State == netplay.Playing {
+		grid.Current(screen, game)
+	}
+	panelX := float64(versusBoard + engine.BoardWidth*blockSize + 10)
+	render.Hold(screen, game, panelX, versusTop, previewSize)
+	render.Next(screen, game, panelX, versusTop+70, previewSize, 45)
+	o.announcer.Draw(screen, versusBoard, versusTop+engine.BoardHeight*blockSize+2)
+
+	opponent := c.Opponent
+	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("%s\nScore: %d\nLines: %d", opponent.Name, opponent.Score, opponent.Lines), miniX, 2)
+	mini := render.Grid{X: miniX, Y: versusTop + 16, Size: miniSize, Gap: 1}
+	ebitenutil.DrawRect(screen, miniX, versusTop+16, engine.BoardWidth*miniSize, engine.BoardHeight*miniSize, render.BoardBackground)
+	mini.Board(screen, opponent.Board)
+
+	if c.State == netplay.Finished {
+		result := "YOU LOSE"
+		switch {
+		case c.Err() != nil:
+			result = "DISCONNECTED\n" + c.Err().Error()
+		case c.Won():
+			result = "YOU WIN"
+		}
+		render.Message(screen, result+"\n\nEsc: quit", 40, versusHeight/2-20)
+	}
+}
+
+func (o *Online) Layout(outsideWidth, outsideHeight int) (int, int) {
+	return onlineW, versusHeight
+}
+
+// join runs "tetris join host:port": one player of a match on a server.
+func join(args []string) {
+	fs := flag.NewFlagSet("join", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: tetris join [flags] host[:port]")
+		fs.PrintDefaults()
+	}
+	name := fs.String("name", defaultName(), "name shown to the opponent")
+	handling := engine.DefaultHandling()
+	cli.HandlingFlags(fs, &handling)
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	if err := handling.Validate(); err != nil {
+		log.Fatal(err)
+	}
+	addr := fs.Arg(0)
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, netplay.DefaultPort)
+	}
+	client, err := netplay.Join(addr, *name, handling)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer client.Close()
+
+	ebiten.SetTPS(engine.TicksPerSecond)
+	ebiten.SetWindowSize(onlineW, versusHeight)
+	ebiten.SetWindowTitle("Tetris - " + addr)
+	if err := ebiten.RunGame(&Online{client: client, server: addr}); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// defaultName is the user's login name, or the high score default.
+func defaultName() string {
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	if name := os.Getenv("USERNAME"); name != "" {
+		return name
+	}
+	return highscore.DefaultName
+}
@@ -0,0 +1,208 @@
+// Package render holds the drawing code shared by the ebiten frontends.
+// Pieces use the guideline colors; each frontend keeps its own layout and
+// cell size.
+package render
+
+import (
+	"image/color"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+
+	"tetris-game/engine"
+)
+
+// PieceColors are the guideline colors of the seven tetrominoes.
+var PieceColors = [...]color.Color{
+	engine.I: color.RGBA{0x00, 0xf0, 0xf0, 0xff}, // Cyan
+	engine.J: color.RGBA{0x00, 0x00, 0xf0, 0xff}, // Blue
+	engine.L: color.RGBA{0xf0, 0xa0, 0x00, 0xff}, // Orange
+	engine.O: color.RGBA{0xf0, 0xf0, 0x00, 0xff}, // Yellow
+	engine.S: color.RGBA{0x00, 0xf0, 0x00, 0xff}, // Green
+	engine.T: color.RGBA{0xa0, 0x00, 0xf0, 0xff}, // Purple
+	engine.Z: color.RGBA{0xf0, 0x00, 0x00, 0xff}, // Red
+}
+
+var (
+	GarbageColor = color.RGBA{0x80, 0x80, 0x80, 0xff}
+	// spentColor draws the hold piece while hold is locked.
+	spentColor = color.RGBA{0x60, 0x60, 0x60, 0xff}
+)
+
+// CellColor returns the color a board cell is drawn in, or nil for an
+// empty cell.
+func CellColor(c engine.Cell) color.Color {
+	if t, ok := c.Piece(); ok {
+		return PieceColors[t]
+	}
+	if c == engine.Garbage {
+		return GarbageColor
+	}
+	return nil
+}
+
+// Grid maps board cells to screen pixels. Cells are Size pixels apart
+// and drawn Gap pixels smaller, with the board's top-left corner at
+// (X, Y).
+type Grid struct {
+	X, Y float64
+	Size float64
+	Gap  float64
+}
+
+// Cell fills the board cell at column x and row y.
+func (g Grid) Cell(screen *ebiten.Image, x, y int, clr color.Color) {
+	ebitenutil.DrawRect(screen, g.X+float64(x)*g.Size, g.Y+float64(y)*g.Size, g.Size-g.Gap, g.Size-g.Gap, clr)
+}
+
+// Board fills every cell of b that is not empty in its own color.
+func (g Grid) Board(screen *ebiten.Image, b engine.Board) {
+	for y, row := range b {
+		for x, cell := range row {
+			if clr := CellColor(cell); clr != nil {
+				g.Cell(screen, x, y, clr)
+			}
+		}
+	}
+}
+
+// Tetromino fills the cells covered by t.
+func (g Grid) Tetromino(screen *ebiten.Image, t *engine.Tetromino, clr color.Color) {
+	for i, row := range t.Shape() {
+		for j, cell := range row {
+			if cell != 0 {
+				g.Cell(screen, t.X+j, t.Y+i, clr)
+			}
+		}
+	}
+}
+
+// Current draws the falling piece of game and its ghost in the color of
+// its type.
+func (g Grid) Current(screen *ebiten.Image, game *engine.Game) {
+	clr := PieceColors[game.CurrentTetromino.Type]
+	if !game.IsGameOver() {
+		g.Ghost(screen, game, clr)
+	}
+	g.Tetromino(screen, game.CurrentTetromino, clr)
+}
+
+// Ghost draws where the current piece would land, in a translucent clr.
+func (g Grid) Ghost(screen *ebiten.Image, game *engine.Game, clr color.Color) {
+	ghost := game.Ghost()
+	g.GhostPiece(screen, &ghost, clr)
+}
+
+// GhostPiece draws ghost in a translucent clr.
+func (g Grid) GhostPiece(screen *ebiten.Image, ghost *engine.Tetromino, clr color.Color) {
+	r, gr, b, _ := clr.RGBA()
+	const alpha = 0x50
+	g.Tetromino(screen, ghost, color.RGBA{
+		R: uint8(r >> 8 * alpha / 0xff),
+		G: uint8(gr >> 8 * alpha / 0xff),
+		B: uint8(b >> 8 * alpha / 0xff),
+		A: alpha,
+	})
+}
+
+// Piece draws t in its spawn orientation as a preview, with the top-left
+// filled cell at (x, y). Empty rows and columns of the shape are skipped.
+func Piece(screen *ebiten.Image, t engine.TetrominoType, x, y, size float64, clr color.Color) {
+	shape := (&engine.Tetromino{Type: t}).Shape()
+	top, left := len(shape), len(shape[0])
+	for i, row := range shape {
+		for j, cell := range row {
+			if cell != 0 {
+				top = min(top, i)
+				left = min(left, j)
+			}
+		}
+	}
+	for i, row := range shape {
+		for j, cell := range row {
+			if cell != 0 {
+				ebitenutil.DrawRect(screen, x+float64(j-left)*size, y+float64(i-top)*size, size-1, size-1, clr)
+			}
+		}
+	}
+}
+
+// Hold draws the hold slot at (x, y) under a label. The piece is drawn in
+// gray while hold is locked until the next piece.
+func Hold(screen *ebiten.Image, game *engine.Game, x, y, size float64) {
+	ebitenutil.DebugPrintAt(screen, "HOLD", int(x), int(y))
+	t, ok := game.HoldPiece()
+	if !ok {
+		return
+	}
+	clr := PieceColors[t]
+	if !game.CanHold() {
+		clr = spentColor
+	}
+	Piece(screen, t, x, y+20, size, clr)
+}
+
+// Next draws the next queue at (x, y) under a label, one piece every
+// spacing pixels.
+func Next(screen *ebiten.Image, game *engine.Game, x, y, size, spacing float64) {
+	next := game.Next()
+	if len(next) == 0 {
+		return
+	}
+	ebitenutil.DebugPrintAt(screen, "NEXT", int(x), int(y))
+	for i, t := range next {
+		Piece(screen, t, x, y+20+float64(i)*spacing, size, PieceColors[t])
+	}
+}
+
+// GarbageMeter draws the garbage waiting to rise on game as a bar of
+// size-pixel rows growing up from the bottom of a board whose top-left
+// corner is at (x, y).
+func GarbageMeter(screen *ebiten.Image, game *engine.Game, x, y, width, size float64) {
+	rows := min(game.PendingGarbage(), engine.BoardHeight)
+	height := float64(rows) * size
+	bottom := y + engine.BoardHeight*size
+	ebitenutil.DrawRect(screen, x, y, width, engine.BoardHeight*size, BoardBackground)
+	ebitenutil.DrawRect(screen, x, bottom-height, width, height, meterColor)
+}
+
+var meterColor = color.RGBA{0xf0, 0x30, 0x30, 0xff}
+
+// BoardBackground is drawn behind boards that need an outline, such as an
+// opponent's small view.
+var BoardBackground = color.RGBA{0x20, 0x20, 0x20, 0xff}
+
+// announceFrames is how long a scoring label stays on screen.
+const announceFrames = 90
+
+// Announcer shows the label of the latest scoring event for a while.
+type Announcer struct {
+	label  string
+	frames int
+}
+
+// Update takes the events of one tick and counts the current label down.
+// Events from the same tick are shown together.
+func (a *Announcer) Update(events []engine.Event) {
+	if a.frames > 0 {
+		a.frames--
+	}
+	var labels []string
+	for _, e := range events {
+		if label := e.Label(); label != "" {
+			labels = append(labels, label)
+		}
+	}
+	if len(labels) > 0 {
+		a.label = strings.Join(labels, "\n")
+		a.frames = announceFrames
+	}
+}
+
+// Draw prints the current label at (x, y) while it is showing.
+func (a *Announcer) Draw(screen *ebiten.Image, x, y int) {
+	if a.frames > 0 {
+		ebitenutil.DebugPrintAt(screen, a.label, x, y)
+	}
+}
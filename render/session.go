@@ -0,0 +1,125 @@
+package render
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+
+	"tetris-game/engine"
+	"tetris-game/highscore"
+	"tetris-game/session"
+)
+
+// commandKeys maps keyboard keys to session commands.
+var commandKeys = map[ebiten.Key]session.Command{
+	ebiten.KeyUp:          session.CommandUp,
+	ebiten.KeyDown:        session.CommandDown,
+	ebiten.KeyLeft:        session.CommandLeft,
+	ebiten.KeyRight:       session.CommandRight,
+	ebiten.KeyEnter:       session.CommandConfirm,
+	ebiten.KeyNumpadEnter: session.CommandConfirm,
+	ebiten.KeyEscape:      session.CommandBack,
+	ebiten.KeyP:           session.CommandPause,
+	ebiten.KeyL:           session.CommandScores,
+	ebiten.KeyR:           session.CommandRestart,
+	ebiten.KeyBackspace:   session.CommandDelete,
+}
+
+// SessionInput reads the menu keys and typed text of this frame and
+// combines them with the game buttons in held.
+func SessionInput(held engine.Input) session.Input {
+	in := session.Input{Held: held, Typed: ebiten.AppendInputChars(nil)}
+	for key, cmd := range commandKeys {
+		if inpututil.IsKeyJustPressed(key) {
+			in.Commands = append(in.Commands, cmd)
+		}
+	}
+	return in
+}
+
+// Overlay draws the screen of every state but Playing with its top-left
+// corner at (x, y).
+func Overlay(screen *ebiten.Image, s *session.Session, x, y int) {
+	var text string
+	switch s.State {
+	case session.Title:
+		text = "TETRIS\n\n" + menuText(s) + "\nArrows: choose  Enter: select\nL: high scores"
+	case session.Paused:
+		text = "PAUSED\n\n" + menuText(s) + "\nP: resume  R: restart"
+	case session.GameOver:
+		text = strings.Join(s.Results(), "\n")
+	case session.HighScoreEntry:
+		text = "NEW HIGH SCORE\nName: " + s.Name + "_\nPress Enter"
+	case session.HighScores:
+		text = leaderboardText(s.Settings.Mode, s.Scores.Top(s.Settings.Mode), s.Rank)
+		if s.Game != nil && !s.Replaying() {
+			text += "\nEnter: title  R: play again"
+		} else {
+			text += "\nEnter: title"
+		}
+	default:
+		return
+	}
+	Message(screen, text, x, y)
+}
+
+// Message prints text over a dark box with its top-left corner at (x, y).
+func Message(screen *ebiten.Image, text string, x, y int) {
+	box(screen, text, x, y)
+	ebitenutil.DebugPrintAt(screen, text, x, y)
+}
+
+// Stats draws the live timer, pace and splits of modes with a goal.
+func Stats(screen *ebiten.Image, s *session.Session, x, y int) {
+	lines := append(s.Stats(), s.Splits()...)
+	if len(lines) > 0 {
+		ebitenutil.DebugPrintAt(screen, strings.Join(lines, "\n"), x, y)
+	}
+}
+
+func menuText(s *session.Session) string {
+	lines, cursor := s.Menu()
+	var b strings.Builder
+	for i, line := range lines {
+		mark := "  "
+		if i == cursor {
+			mark = "> "
+		}
+		b.WriteString(mark + line + "\n")
+	}
+	return b.String()
+}
+
+// leaderboardText lays entries out as a table, marking the entry at
+// highlight.
+func leaderboardText(mode string, entries []highscore.Entry, highlight int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "HIGH SCORES: %s\n", strings.ToUpper(mode))
+	fmt.Fprintf(&b, "%3s %-12s %7s %4s %2s %8s\n", "#", "NAME", "SCORE", "LINE", "LV", "TIME")
+	for i, e := range entries {
+		mark := " "
+		if i == highlight {
+			mark = ">"
+		}
+		fmt.Fprintf(&b, "%s%2d %-12s %7d %4d %2d %8s\n", mark, i+1, e.Name, e.Score, e.Lines, e.Level, highscore.FormatDuration(e.Duration))
+	}
+	if len(entries) == 0 {
+		b.WriteString("   no games yet\n")
+	}
+	return b.String()
+}
+
+// box darkens the area behind text so it reads over the board.
+func box(screen *ebiten.Image, text string, x, y int) {
+	const charWidth, lineHeight, pad = 6, 16, 4
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	width := 0
+	for _, line := range lines {
+		width = max(width, len([]rune(line)))
+	}
+	ebitenutil.DrawRect(screen, float64(x-pad), float64(y-pad), float64(width*charWidth+2*pad), float64(len(lines)*lineHeight+2*pad), color.RGBA{0, 0, 0, 0xe0})
+}
@@ -0,0 +1,198 @@
+package session
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+
+	"tetris-game/engine"
+)
+
+// Settings are the options picked on the title menu.
+type Settings struct {
+	Mode       string
+	Gravity    string
+	Randomizer string
+	NextCount  int
+}
+
+func settingsFrom(rules engine.Rules) Settings {
+	s := Settings{
+		Mode:       engine.ModeEndless,
+		Gravity:    rules.Gravity,
+		Randomizer: rules.Randomizer,
+		NextCount:  rules.NextCount,
+	}
+	if s.Gravity == "" {
+		s.Gravity = engine.GravityFixed
+	}
+	if s.Randomizer == "" {
+		s.Randomizer = engine.RandomizerBag
+	}
+	return s
+}
+
+func (s Settings) apply(rules engine.Rules) engine.Rules {
+	mode, _ := engine.LookupMode(s.Mode)
+	rules = mode.Rules(rules)
+	rules.Gravity = s.Gravity
+	rules.Randomizer = s.Randomizer
+	rules.NextCount = s.NextCount
+	return rules
+}
+
+// The choices offered for each setting.
+var (
+	modes       = modeNames()
+	gravities   = []string{engine.GravityGuideline, engine.GravityNES, engine.GravityFixed}
+	randomizers = []string{engine.RandomizerBag, engine.RandomizerHistory, engine.RandomizerRandom}
+)
+
+func modeNames() []string {
+	var names []string
+	for _, m := range engine.Modes() {
+		names = append(names, m.Name)
+	}
+	return names
+}
+
+// menu is a cursor over a list of items.
+type menu struct {
+	items  int
+	cursor int
+}
+
+func (m *menu) move(in Input) {
+	if in.has(CommandUp) {
+		m.cursor = (m.cursor + m.items - 1) % m.items
+	}
+	if in.has(CommandDown) {
+		m.cursor = (m.cursor + 1) % m.items
+	}
+}
+
+const (
+	titleStart = iota
+	titleMode
+	titleGravity
+	titleRandomizer
+	titleNext
+	titleScores
+)
+
+var titleItems = [...]string{"Start", "Mode", "Gravity", "Randomizer", "Next", "High scores"}
+
+func (s *Session) updateTitle(in Input) {
+	if in.has(CommandScores) {
+		s.Rank = -1
+		s.State = HighScores
+		return
+	}
+	s.title.move(in)
+	step := 0
+	if in.has(CommandLeft) {
+		step--
+	}
+	if in.has(CommandRight) {
+		step++
+	}
+	if step != 0 {
+		s.changeSetting(s.title.cursor, step)
+	}
+	if !in.has(CommandConfirm) {
+		return
+	}
+	switch s.title.cursor {
+	case titleScores:
+		s.Rank = -1
+		s.State = HighScores
+	default:
+		s.Start()
+	}
+}
+
+func (s *Session) changeSetting(item, step int) {
+	switch item {
+	case titleMode:
+		s.Settings.Mode = cycle(modes, s.Settings.Mode, step)
+	case titleGravity:
+		s.Settings.Gravity = cycle(gravities, s.Settings.Gravity, step)
+	case titleRandomizer:
+		options := randomizers
+		if len(s.Rules.Sequence) > 0 {
+			options = append(slices.Clone(options), engine.RandomizerSequence)
+		}
+		s.Settings.Randomizer = cycle(options, s.Settings.Randomizer, step)
+	case titleNext:
+		s.Settings.NextCount = (s.Settings.NextCount + step + engine.MaxNextCount + 1) % (engine.MaxNextCount + 1)
+	}
+}
+
+// cycle returns the option step places after current, wrapping around.
+func cycle(options []string, current string, step int) string {
+	i := slices.Index(options, current)
+	return options[(i+step+len(options))%len(options)]
+}
+
+const (
+	pauseResume = iota
+	pauseRestart
+	pauseTitle
+)
+
+var pauseItems = [...]string{"Resume", "Restart", "Quit to title"}
+
+func (s *Session) updatePause(in Input) {
+	if in.has(CommandPause) || in.has(CommandBack) {
+		s.State = Playing
+		return
+	}
+	s.pause.move(in)
+	cursor := s.pause.cursor
+	if in.has(CommandRestart) {
+		cursor = pauseRestart
+	} else if !in.has(CommandConfirm) {
+		return
+	}
+	switch cursor {
+	case pauseResume:
+		s.State = Playing
+	case pauseRestart:
+		if s.player != nil {
+			s.State = Playing
+			return
+		}
+		s.Start()
+	case pauseTitle:
+		s.Quit()
+	}
+}
+
+// Menu returns the lines of the title or pause menu and the line the
+// cursor is on, followed on the title by what the chosen mode is about.
+// Other states have no menu.
+func (s *Session) Menu() ([]string, int) {
+	switch s.State {
+	case Title:
+		values := [...]string{
+			titleMode:       s.Settings.Mode,
+			titleGravity:    s.Settings.Gravity,
+			titleRandomizer: s.Settings.Randomizer,
+			titleNext:       strconv.Itoa(s.Settings.NextCount),
+		}
+		lines := make([]string, len(titleItems))
+		for i, item := range titleItems {
+			lines[i] = item
+			if i < len(values) && values[i] != "" {
+				lines[i] = fmt.Sprintf("%s: < %s >", item, values[i])
+			}
+		}
+		if mode, ok := engine.LookupMode(s.Settings.Mode); ok && mode.Description != "" {
+			lines = append(lines, "", mode.Description)
+		}
+		return lines, s.title.cursor
+	case Paused:
+		return pauseItems[:], s.pause.cursor
+	}
+	return nil, 0
+}
@@ -0,0 +1,277 @@
+// Package session runs the screens around a game: the title menu,
+// pausing, game over and high score entry. Frontends turn their keys into
+// an Input once per frame, pass it to Update and draw whichever State the
+// session is in.
+package session
+
+import (
+	"log"
+	"strings"
+	"unicode"
+
+	"tetris-game/engine"
+	"tetris-game/highscore"
+)
+
+// State is the screen a session is on.
+type State int
+
+const (
+	Title State = iota
+	Playing
+	Paused
+	GameOver
+	HighScoreEntry
+	HighScores
+)
+
+var stateNames = [...]string{"title", "playing", "paused", "game over", "high score entry", "high scores"}
+
+func (s State) String() string {
+	return stateNames[s]
+}
+
+// Command is a menu key, pressed on one frame.
+type Command int
+
+const (
+	CommandUp Command = iota
+	CommandDown
+	CommandLeft
+	CommandRight
+	CommandConfirm // Enter
+	CommandBack    // Esc; also pauses while playing
+	CommandPause   // P
+	CommandScores  // L, the high score table from the title
+	CommandRestart // R, a new game from the pause menu or high scores
+	CommandDelete  // Backspace
+)
+
+// Player chooses the buttons to hold on each tick of a game, in place of
+// the keyboard; ai.Bot is one.
+type Player interface {
+	Input(game *engine.Game) engine.Input
+}
+
+// Input is what a frontend read from its keyboard on one frame.
+type Input struct {
+	// Held is passed to Game.Tick while playing.
+	Held engine.Input
+	// Commands are the menu keys pressed this frame.
+	Commands []Command
+	// Typed is the text typed this frame, used for names.
+	Typed []rune
+}
+
+func (in Input) has(c Command) bool {
+	for _, cmd := range in.Commands {
+		if cmd == c {
+			return true
+		}
+	}
+	return false
+}
+
+// gameOverTicks is how long the game over screen shows before moving on.
+const gameOverTicks = 2 * engine.TicksPerSecond
+
+type Session struct {
+	State    State
+	Game     *engine.Game
+	Settings Settings
+
+	// Seed is used for every game; zero picks a new one each time.
+	Seed     int64
+	Rules    engine.Rules
+	Handling engine.Handling
+
+	// Scores is saved to ScoresPath when a name is entered; an empty
+	// path keeps it in memory.
+	Scores     *highscore.Table
+	ScoresPath string
+	// Name is the name being typed on HighScoreEntry.
+	Name string
+	// Rank is where the last entry placed in Scores, or -1.
+	Rank int
+
+	// Record makes every game keep a Recorder.
+	Record   bool
+	Recorder *engine.Recorder
+
+	// Bot plays instead of the keyboard when set. Its games are not
+	// entered in the high scores; a new one starts after each game over.
+	Bot Player
+
+	title  menu
+	pause  menu
+	timer  int
+	player *engine.ReplayPlayer
+}
+
+// New starts a session on the title screen. The settings start from
+// rules, which also supply everything the menu does not change.
+func New(rules engine.Rules, handling engine.Handling, scores *highscore.Table, scoresPath string) *Session {
+	if scores == nil {
+		scores = highscore.NewTable()
+	}
+	s := &Session{
+		Settings:   settingsFrom(rules),
+		Rules:      rules,
+		Handling:   handling,
+		Scores:     scores,
+		ScoresPath: scoresPath,
+		Rank:       -1,
+	}
+	s.title = menu{items: len(titleItems)}
+	s.pause = menu{items: len(pauseItems)}
+	return s
+}
+
+// Start begins a new game with the current settings.
+func (s *Session) Start() {
+	seed := s.Seed
+	if seed == 0 {
+		seed = engine.NewSeed()
+	}
+	s.Game = engine.NewGame(seed, s.Settings.apply(s.Rules))
+	s.Game.SetHandling(s.Handling)
+	s.Recorder = nil
+	if s.Record {
+		s.Recorder = engine.NewRecorder(s.Game)
+	}
+	s.player = nil
+	s.play()
+}
+
+// StartReplay plays replay back, taking the input from the replay
+// instead of the frontend. The result is not entered in the high scores.
+func (s *Session) StartReplay(replay *engine.Replay) {
+	s.Game = replay.NewGame()
+	s.Recorder = nil
+	s.player = replay.Player()
+	s.play()
+}
+
+// Replaying reports whether the current game is a replay.
+func (s *Session) Replaying() bool {
+	return s.player != nil
+}
+
+// Quit abandons the game and returns to the title screen.
+func (s *Session) Quit() {
+	s.State = Title
+}
+
+func (s *Session) play() {
+	s.State = Playing
+	s.Rank = -1
+	s.Name = ""
+}
+
+// Update advances the session by one frame and returns the events of the
+// game tick it played, if any.
+func (s *Session) Update(in Input) []engine.Event {
+	switch s.State {
+	case Title:
+		s.updateTitle(in)
+	case Playing:
+		return s.updatePlaying(in)
+	case Paused:
+		s.updatePause(in)
+	case GameOver:
+		// Results of a completed game stay up until dismissed, unless
+		// the bot is playing.
+		s.timer++
+		if in.has(CommandConfirm) || s.timer >= gameOverTicks && (!s.Game.Completed() || s.Bot != nil) {
+			s.finish()
+		}
+	case HighScoreEntry:
+		s.updateName(in)
+	case HighScores:
+		if in.has(CommandRestart) && s.Game != nil && s.player == nil {
+			s.Start()
+			return nil
+		}
+		if in.has(CommandConfirm) || in.has(CommandBack) || in.has(CommandScores) {
+			s.State = Title
+		}
+	}
+	return nil
+}
+
+func (s *Session) updatePlaying(in Input) []engine.Event {
+	if in.has(CommandPause) || in.has(CommandBack) {
+		s.State = Paused
+		s.pause.cursor = 0
+		return nil
+	}
+	held := in.Held
+	if s.player != nil {
+		var ok bool
+		if held, ok = s.player.Next(); !ok {
+			s.Quit()
+			return nil
+		}
+	} else if s.Bot != nil {
+		held = s.Bot.Input(s.Game)
+	}
+	if s.Recorder != nil {
+		s.Recorder.Record(held)
+	}
+	s.Game.Tick(held)
+	events := s.Game.Events()
+	if s.Game.IsGameOver() {
+		s.State = GameOver
+		s.timer = 0
+	}
+	return events
+}
+
+// finish leaves the game over screen for name entry if the score made
+// the table, or for the table itself. Replays go back to the title and
+// the bot plays again.
+func (s *Session) finish() {
+	if s.player != nil {
+		s.Quit()
+		return
+	}
+	if s.Bot != nil {
+		s.Start()
+		return
+	}
+	if s.Scores.Qualifies(s.Settings.Mode, highscore.NewEntry("", s.Game)) {
+		s.State = HighScoreEntry
+		return
+	}
+	s.State = HighScores
+}
+
+func (s *Session) updateName(in Input) {
+	name := []rune(s.Name)
+	for _, r := range in.Typed {
+		if unicode.IsPrint(r) && len(name) < highscore.MaxNameLength {
+			name = append(name, r)
+		}
+	}
+	if in.has(CommandDelete) && len(name) > 0 {
+		name = name[:len(name)-1]
+	}
+	s.Name = string(name)
+	if !in.has(CommandConfirm) {
+		return
+	}
+	entry := highscore.NewEntry(strings.TrimSpace(s.Name), s.Game)
+	s.Rank = s.Scores.Add(s.Settings.Mode, entry)
+	if s.ScoresPath != "" {
+		if err := s.Scores.Save(s.ScoresPath); err != nil {
+			log.Print(err)
+		}
+	}
+	s.State = HighScores
+}
+
+// BoardVisible reports whether the game should be drawn. The board is
+// hidden on the title screen and while paused.
+func (s *Session) BoardVisible() bool {
+	return s.Game != nil && s.State != Title && s.State != Paused
+}
@@ -0,0 +1,126 @@
+package session
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"tetris-game/engine"
+	"tetris-game/highscore"
+)
+
+// Stats returns the live stats of a mode with a goal: the timer, the
+// pieces per second and how far the goal is. Endless games have none.
+func (s *Session) Stats() []string {
+	g := s.Game
+	if g == nil || !g.Rules().HasGoal() {
+		return nil
+	}
+	rules := g.Rules()
+	if rules.TimeLimit > 0 {
+		return []string{
+			"Time " + highscore.FormatDuration(g.TimeLeft()),
+			fmt.Sprintf("PPS  %.2f", g.PiecesPerSecond()),
+		}
+	}
+	stats := []string{
+		"Time " + highscore.FormatDuration(g.Elapsed()),
+		fmt.Sprintf("PPS  %.2f", g.PiecesPerSecond()),
+	}
+	if rules.LevelGoal > 0 {
+		stats = append(stats, fmt.Sprintf("Level %d/%d", min(g.Level(), rules.LevelGoal), rules.LevelGoal))
+	}
+	if rules.LineGoal > 0 {
+		stats = append(stats, fmt.Sprintf("Left %d", max(0, rules.LineGoal-g.Lines())))
+	}
+	if rules.GarbageLines > 0 {
+		stats = append(stats, fmt.Sprintf("Left %d", g.GarbageLeft()))
+	}
+	return stats
+}
+
+// Splits returns a line for every split reached so far, each compared
+// with the same split of the personal best. Only modes ranked by time
+// show splits; a marathon's fifteen would not fit on screen.
+func (s *Session) Splits() []string {
+	g := s.Game
+	if g == nil || g.Rules().LineGoal == 0 {
+		return nil
+	}
+	if mode, _ := engine.LookupMode(g.Rules().Mode); !mode.RankByTime {
+		return nil
+	}
+	best, ok := s.Scores.Best(g.Rules().Mode)
+	var lines []string
+	for i, split := range g.Splits() {
+		line := fmt.Sprintf("%3d %s", (i+1)*engine.SplitLines, highscore.FormatDuration(split))
+		if ok && i < len(best.Splits) {
+			line += " " + formatDelta(split-best.Splits[i])
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// Results returns the lines of the game over screen: the final score for
+// a game that topped out, or the full results of a completed one.
+func (s *Session) Results() []string {
+	g := s.Game
+	if !g.Completed() {
+		return []string{
+			"GAME OVER",
+			fmt.Sprintf("Score: %d", g.Score()),
+			fmt.Sprintf("Seed: %d", g.Seed()),
+		}
+	}
+	mode := g.Rules().Mode
+	lines := []string{
+		strings.ToUpper(mode) + " COMPLETE",
+		"",
+		"Time   " + highscore.FormatDuration(g.Elapsed()),
+		fmt.Sprintf("Lines  %d", g.Lines()),
+		fmt.Sprintf("Pieces %d", g.Pieces()),
+		fmt.Sprintf("PPS    %.2f", g.PiecesPerSecond()),
+		fmt.Sprintf("Score  %d", g.Score()),
+	}
+	if s.player == nil {
+		lines = append(lines, s.personalBest())
+	}
+	lines = append(lines, "")
+	lines = append(lines, s.Splits()...)
+	lines = append(lines, "", fmt.Sprintf("Seed: %d", g.Seed()), "Enter: continue")
+	return lines
+}
+
+// personalBest compares a completed game with the best of its mode, by
+// time or by score as the mode is ranked.
+func (s *Session) personalBest() string {
+	g := s.Game
+	best, ok := s.Scores.Best(g.Rules().Mode)
+	mode, _ := engine.LookupMode(g.Rules().Mode)
+	switch {
+	case !ok:
+		return "New personal best!"
+	case mode.RankByTime:
+		if g.Elapsed() < best.Duration {
+			return "New personal best!"
+		}
+		return "Best   " + highscore.FormatDuration(best.Duration) + " " + formatDelta(g.Elapsed()-best.Duration)
+	default:
+		if g.Score() > best.Score {
+			return "New personal best!"
+		}
+		return fmt.Sprintf("Best   %d %+d", best.Score, g.Score()-best.Score)
+	}
+}
+
+// formatDelta prints the difference to a personal best with its sign,
+// e.g. "-0.512" when ahead.
+func formatDelta(d time.Duration) string {
+	sign := "+"
+	if d < 0 {
+		sign = "-"
+		d = -d
+	}
+	return fmt.Sprintf("%s%d.%03d", sign, d.Milliseconds()/1000, d.Milliseconds()%1000)
+}
@@ -0,0 +1,41 @@
+package session
+
+import (
+	"testing"
+
+	"tetris-game/engine"
+	"tetris-game/highscore"
+)
+
+func TestSplitsOnlyForTimedModes(t *testing.T) {
+	tests := []struct {
+		mode string
+		want int
+	}{
+		{engine.ModeSprint, 1},
+		{engine.ModeMarathon, 0},
+		{engine.ModeEndless, 0},
+	}
+	for _, tt := range tests {
+		mode, _ := engine.LookupMode(tt.mode)
+		rules := mode.Rules(engine.DefaultRules())
+		rules.Randomizer = engine.RandomizerSequence
+		rules.Sequence = []engine.TetrominoType{engine.I}
+		s := &Session{Game: engine.NewGame(1, rules), Scores: highscore.NewTable()}
+		// Fill the board a column at a time with vertical I pieces, which
+		// clears four rows every ten pieces.
+		for i := 0; s.Game.Lines() < engine.SplitLines && !s.Game.IsGameOver(); i++ {
+			s.Game.Tick(engine.InputRotateRight)
+			x := i % engine.BoardWidth
+			for s.Game.CurrentTetromino.X+2 > x && s.Game.MoveLeft() {
+			}
+			for s.Game.CurrentTetromino.X+2 < x && s.Game.MoveRight() {
+			}
+			s.Game.HardDrop()
+			s.Game.Tick(0)
+		}
+		if got := len(s.Splits()); got != tt.want {
+			t.Errorf("%s: %d splits after %d lines, want %d", tt.mode, got, s.Game.Lines(), tt.want)
+		}
+	}
+}
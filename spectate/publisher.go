@@ -0,0 +1,138 @@
+package spectate
+
+import (
+	"encoding/json"
+	"log"
+	"net"
+	"sync"
+
+	"tetris-game/engine"
+)
+
+// viewerBuffer is how many messages a viewer may fall behind before it
+// is dropped, so a slow viewer never holds up the game.
+const viewerBuffer = 256
+
+// Publisher serves the game it is given each tick to every viewer that
+// connects.
+type Publisher struct {
+	listener net.Listener
+
+	mu      sync.Mutex
+	viewers []*viewer
+	game    *engine.Game
+	state   State
+}
+
+type viewer struct {
+	conn     net.Conn
+	messages chan Message
+	// done is closed once the viewer stops writing, and Publish drops it.
+	done chan struct{}
+	// fresh viewers are sent a snapshot on the next Publish.
+	fresh bool
+}
+
+// Listen starts publishing on the TCP address addr. Viewers are accepted
+// in the background until Close.
+func Listen(addr string) (*Publisher, error) {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	p := &Publisher{listener: l}
+	go p.accept()
+	return p, nil
+}
+
+// Addr returns the address viewers connect to.
+func (p *Publisher) Addr() net.Addr {
+	return p.listener.Addr()
+}
+
+func (p *Publisher) accept() {
+	for {
+		conn, err := p.listener.Accept()
+		if err != nil {
+			return
+		}
+		v := &viewer{conn: conn, messages: make(chan Message, viewerBuffer), done: make(chan struct{}), fresh: true}
+		go v.write()
+		p.mu.Lock()
+		p.viewers = append(p.viewers, v)
+		p.mu.Unlock()
+	}
+}
+
+// write sends the viewer its messages until the channel is closed or the
+// connection fails.
+func (v *viewer) write() {
+	defer close(v.done)
+	defer v.conn.Close()
+	enc := json.NewEncoder(v.conn)
+	for m := range v.messages {
+		if err := enc.Encode(m); err != nil {
+			log.Printf("spectate: %s: %v", v.conn.RemoteAddr(), err)
+			return
+		}
+	}
+}
+
+// Publish sends viewers the state of game after a tick with events.
+// Viewers that just connected, and all of them when game is a new game,
+// get a snapshot; the rest get what changed.
+func (p *Publisher) Publish(game *engine.Game, events []engine.Event) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.viewers) == 0 {
+		p.game = nil
+		return
+	}
+	state := StateOf(game)
+	frame := game.Frame()
+	snapshot := Message{Type: TypeSnapshot, Version: StreamVersion, Frame: frame, State: &state, Events: events}
+	delta, changed := state.diff(p.state)
+	delta.Frame = frame
+	delta.Events = events
+	newGame := game != p.game
+	p.game, p.state = game, state
+
+	live := p.viewers[:0]
+	for _, v := range p.viewers {
+		select {
+		case <-v.done:
+			continue
+		default:
+		}
+		m := delta
+		switch {
+		case v.fresh || newGame:
+			m = snapshot
+			v.fresh = false
+		case !changed && len(events) == 0:
+			live = append(live, v)
+			continue
+		}
+		select {
+		case v.messages <- m:
+			live = append(live, v)
+		default:
+			log.Printf("spectate: dropping %s, too far behind", v.conn.RemoteAddr())
+			close(v.messages)
+		}
+	}
+	clear(p.viewers[len(live):])
+	p.viewers = live
+}
+
+// Close stops accepting viewers and disconnects those watching.
+func (p *Publisher) Close() error {
+	err := p.listener.Close()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, v := range p.viewers {
+		close(v.messages)
+	}
+	p.viewers = nil
+	return err
+}
@@ -0,0 +1,55 @@
+package spectate
+
+import (
+	"net"
+	"runtime"
+	"testing"
+	"time"
+
+	"tetris-game/engine"
+)
+
+func (p *Publisher) count() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.viewers)
+}
+
+// Viewers that hang up must be dropped, and their goroutines end.
+func TestViewersLeave(t *testing.T) {
+	p, err := Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+	before := runtime.NumGoroutine()
+
+	const viewers = 20
+	var conns []net.Conn
+	for range viewers {
+		conn, err := net.Dial("tcp", p.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		conns = append(conns, conn)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for p.count() < viewers {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d of %d viewers accepted", p.count(), viewers)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	for _, conn := range conns {
+		conn.Close()
+	}
+	for p.count() > 0 || runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d viewers and %d goroutines left, want 0 and %d", p.count(), runtime.NumGoroutine(), before)
+		}
+		// A new game each time sends every viewer a snapshot.
+		p.Publish(engine.NewGame(1, engine.DefaultRules()), nil)
+		time.Sleep(time.Millisecond)
+	}
+}
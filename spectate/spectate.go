@@ -0,0 +1,177 @@
+// Package spectate streams a running game to read-only viewers over TCP.
+// The stream is JSON lines: a viewer is sent a snapshot of the whole
+// game as it connects, then a delta every tick something changed,
+// carrying only the rows and fields that differ from the last message.
+package spectate
+
+import (
+	"fmt"
+	"slices"
+
+	"tetris-game/engine"
+)
+
+// StreamVersion is sent in every snapshot. Viewers refuse other versions.
+const StreamVersion = 1
+
+// DefaultPort is the TCP port games publish on unless told otherwise.
+const DefaultPort = "7778"
+
+// Message types.
+const (
+	TypeSnapshot = "snapshot"
+	TypeDelta    = "delta"
+)
+
+// State is what a viewer draws: the same board and pieces a frontend
+// renders for the player.
+type State struct {
+	// Board has a string per row, as written by engine.EncodeBoard.
+	Board   []string               `json:"board"`
+	Piece   engine.Tetromino       `json:"piece"`
+	Hold    []engine.TetrominoType `json:"hold,omitempty"`
+	CanHold bool                   `json:"can_hold"`
+	Next    []engine.TetrominoType `json:"next"`
+	Score   int                    `json:"score"`
+	Level   int                    `json:"level"`
+	Lines   int                    `json:"lines"`
+	Over    bool                   `json:"over"`
+}
+
+// Row is a changed board row in a delta.
+type Row struct {
+	Y     int    `json:"y"`
+	Cells string `json:"cells"`
+}
+
+// Message is one line of the stream. A snapshot sets Version and State;
+// a delta sets the fields that changed, and Events holds what happened
+// on the tick either was sent.
+type Message struct {
+	Type    string         `json:"type"`
+	Version int            `json:"version,omitempty"`
+	Frame   int            `json:"frame"`
+	State   *State         `json:"state,omitempty"`
+	Events  []engine.Event `json:"events,omitempty"`
+
+	Rows    []Row                  `json:"rows,omitempty"`
+	Piece   *engine.Tetromino      `json:"piece,omitempty"`
+	Hold    []engine.TetrominoType `json:"hold,omitempty"`
+	CanHold *bool                  `json:"can_hold,omitempty"`
+	Next    []engine.TetrominoType `json:"next,omitempty"`
+	Score   *int                   `json:"score,omitempty"`
+	Level   *int                   `json:"level,omitempty"`
+	Lines   *int                   `json:"lines,omitempty"`
+	Over    *bool                  `json:"over,omitempty"`
+}
+
+// StateOf captures the state of game.
+func StateOf(game *engine.Game) State {
+	s := State{
+		Board:   engine.EncodeBoard(game.Board),
+		Piece:   *game.CurrentTetromino,
+		CanHold: game.CanHold(),
+		Next:    slices.Clone(game.Next()),
+		Score:   game.Score(),
+		Level:   game.Level(),
+		Lines:   game.Lines(),
+		Over:    game.IsGameOver(),
+	}
+	if t, ok := game.HoldPiece(); ok {
+		s.Hold = []engine.TetrominoType{t}
+	}
+	return s
+}
+
+// DecodeBoard returns the board of s for drawing.
+func (s State) DecodeBoard() engine.Board {
+	return engine.DecodeBoard(s.Board)
+}
+
+// diff returns the delta from old to s, and whether anything changed.
+func (s State) diff(old State) (Message, bool) {
+	m := Message{Type: TypeDelta}
+	changed := false
+	for y, row := range s.Board {
+		if y >= len(old.Board) || old.Board[y] != row {
+			m.Rows = append(m.Rows, Row{Y: y, Cells: row})
+			changed = true
+		}
+	}
+	if s.Piece != old.Piece {
+		m.Piece = &s.Piece
+		changed = true
+	}
+	if !slices.Equal(s.Hold, old.Hold) {
+		m.Hold = s.Hold
+		changed = true
+	}
+	if !slices.Equal(s.Next, old.Next) {
+		m.Next = s.Next
+		changed = true
+	}
+	set := func(field **int, v, was int) {
+		if v != was {
+			*field = &v
+			changed = true
+		}
+	}
+	set(&m.Score, s.Score, old.Score)
+	set(&m.Level, s.Level, old.Level)
+	set(&m.Lines, s.Lines, old.Lines)
+	if s.CanHold != old.CanHold {
+		m.CanHold = &s.CanHold
+		changed = true
+	}
+	if s.Over != old.Over {
+		m.Over = &s.Over
+		changed = true
+	}
+	return m, changed
+}
+
+// Apply updates s with a snapshot or a delta. A message with a piece that
+// could not be drawn is refused and leaves s untouched.
+func (s *State) Apply(m Message) error {
+	piece := m.Piece
+	if m.Type == TypeSnapshot && m.State != nil {
+		piece = &m.State.Piece
+	}
+	if piece != nil && (piece.Rotation < engine.R0 || piece.Rotation > engine.R270) {
+		return fmt.Errorf("spectate: piece rotation %d out of range", piece.Rotation)
+	}
+	if m.Type == TypeSnapshot && m.State != nil {
+		*s = *m.State
+		return nil
+	}
+	for _, row := range m.Rows {
+		if row.Y >= 0 && row.Y < len(s.Board) {
+			s.Board[row.Y] = row.Cells
+		}
+	}
+	if m.Piece != nil {
+		s.Piece = *m.Piece
+	}
+	if m.Hold != nil {
+		s.Hold = m.Hold
+	}
+	if m.Next != nil {
+		s.Next = m.Next
+	}
+	if m.CanHold != nil {
+		s.CanHold = *m.CanHold
+	}
+	if m.Score != nil {
+		s.Score = *m.Score
+	}
+	if m.Level != nil {
+		s.Level = *m.Level
+	}
+	if m.Lines != nil {
+		s.Lines = *m.Lines
+	}
+	if m.Over != nil {
+		s.Over = *m.Over
+	}
+	return nil
+}
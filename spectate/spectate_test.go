@@ -0,0 +1,72 @@
+package spectate
+
+import (
+	"testing"
+	"time"
+
+	"tetris-game/engine"
+)
+
+func TestApply(t *testing.T) {
+	game := engine.NewGame(1, engine.DefaultRules())
+	good := StateOf(game)
+	bad := good
+	bad.Piece.Rotation = 7
+	badPiece := bad.Piece
+
+	tests := []struct {
+		name    string
+		m       Message
+		wantErr bool
+	}{
+		{"snapshot", Message{Type: TypeSnapshot, State: &good}, false},
+		{"delta", Message{Type: TypeDelta, Piece: &good.Piece}, false},
+		{"snapshot rotation out of range", Message{Type: TypeSnapshot, State: &bad}, true},
+		{"delta rotation out of range", Message{Type: TypeDelta, Piece: &badPiece}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := StateOf(game)
+			err := s.Apply(tt.m)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error %v, want error %v", err, tt.wantErr)
+			}
+			if s.Piece.Rotation < engine.R0 || s.Piece.Rotation > engine.R270 {
+				t.Errorf("state took rotation %d", s.Piece.Rotation)
+			}
+		})
+	}
+}
+
+// A viewer sent a piece it cannot draw stops with an error.
+func TestWatchBadRotation(t *testing.T) {
+	p, err := Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+	v, err := Watch(p.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer v.Close()
+	waitFor(t, "viewer to connect", func() bool { return p.count() > 0 })
+	game := engine.NewGame(1, engine.DefaultRules())
+	game.CurrentTetromino.Rotation = 5
+	p.Publish(game, nil)
+	waitFor(t, "viewer to stop", func() bool { return v.Err() != nil })
+	if _, _, seen := v.State(); seen {
+		t.Error("viewer took the bad snapshot")
+	}
+}
+
+func waitFor(t *testing.T, what string, done func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !done() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,102 @@
+package spectate
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"tetris-game/engine"
+)
+
+// Viewer follows the stream of a publishing game. Its state is updated in
+// the background; read it with State.
+type Viewer struct {
+	conn net.Conn
+
+	mu     sync.Mutex
+	state  State
+	frame  int
+	seen   bool
+	events []engine.Event
+	err    error
+}
+
+// Watch connects to the game published at addr.
+func Watch(addr string) (*Viewer, error) {
+	conn, err := net.DialTimeout("tcp", addr, 5*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	v := &Viewer{conn: conn}
+	go v.read()
+	return v, nil
+}
+
+func (v *Viewer) read() {
+	scanner := bufio.NewScanner(v.conn)
+	scanner.Buffer(make([]byte, 4096), 64*1024)
+	for scanner.Scan() {
+		var m Message
+		err := json.Unmarshal(scanner.Bytes(), &m)
+		if err == nil && m.Type == TypeSnapshot && m.Version != StreamVersion {
+			err = fmt.Errorf("spectate: stream version %d, want %d", m.Version, StreamVersion)
+		}
+		v.mu.Lock()
+		if err == nil && (m.Type == TypeSnapshot || v.seen) {
+			err = v.state.Apply(m)
+		}
+		if err != nil {
+			v.err = err
+			v.mu.Unlock()
+			v.conn.Close()
+			return
+		}
+		if m.Type == TypeSnapshot || v.seen {
+			v.seen = true
+			v.frame = m.Frame
+			v.events = append(v.events, m.Events...)
+		}
+		v.mu.Unlock()
+	}
+	v.mu.Lock()
+	if v.err == nil {
+		v.err = scanner.Err()
+		if v.err == nil {
+			v.err = fmt.Errorf("spectate: %s stopped publishing", v.conn.RemoteAddr())
+		}
+	}
+	v.mu.Unlock()
+}
+
+// State returns a copy of the last state received, the frame it is from
+// and whether a snapshot has arrived yet.
+func (v *Viewer) State() (State, int, bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	s := v.state
+	s.Board = append([]string(nil), s.Board...)
+	return s, v.frame, v.seen
+}
+
+// Events returns the events received since the last call.
+func (v *Viewer) Events() []engine.Event {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	events := v.events
+	v.events = nil
+	return events
+}
+
+// Err returns why the stream ended, or nil while it is running.
+func (v *Viewer) Err() error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.err
+}
+
+func (v *Viewer) Close() error {
+	return v.conn.Close()
+}
@@ -1,196 +1,118 @@
 package main
 
 import (
-	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"flag"
+	"fmt"
 	"image/color"
 	"log"
-	"math/rand"
-	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+
+	"tetris-game/cli"
+	"tetris-game/engine"
+	"tetris-game/highscore"
+	"tetris-game/render"
+	"tetris-game/session"
 )
 
 const (
-	screenWidth  = 200
-	screenHeight = 400
 	blockSize    = 20
-	rows         = screenHeight / blockSize
-	cols         = screenWidth / blockSize
-	initialSpeed = 30 // 控制下落速度的初始值
-)
-
-var (
-	colors = []color.Color{
-		color.RGBA{0x00, 0x00, 0x00, 0xff}, // Black
-		color.RGBA{0xff, 0x00, 0x00, 0xff}, // Red
-		color.RGBA{0x00, 0xff, 0x00, 0xff}, // Green
-		color.RGBA{0x00, 0x00, 0xff, 0xff}, // Blue
-		color.RGBA{0xff, 0xff, 0x00, 0xff}, // Yellow
-		color.RGBA{0xff, 0x00, 0xff, 0xff}, // Magenta
-		color.RGBA{0x00, 0xff, 0xff, 0xff}, // Cyan
-		color.RGBA{0xff, 0xff, 0xff, 0xff}, // White
-	}
+	previewSize  = 15
+	panelWidth   = 80
+	screenWidth  = engine.BoardWidth*blockSize + panelWidth
+	screenHeight = engine.BoardHeight * blockSize
 )
 
 type Game struct {
-	board        [rows][cols]int
-	currentPiece *Piece
-	tick         int
-	speed        int
-}
-
-type Piece struct {
-	x, y   int
-	shape  [][]int
-	color  int
-}
-
-func NewPiece() *Piece {
-	shapes := [][][]int{
-		{{1, 1, 1, 1}}, // I
-		{{1, 1}, {1, 1}}, // O
-		{{0, 1, 0}, {1, 1, 1}}, // T
-		{{1, 1, 0}, {0, 1, 1}}, // S
-		{{0, 1, 1}, {1, 1, 0}}, // Z
-		{{1, 0, 0}, {1, 1, 1}}, // L
-		{{0, 0, 1}, {1, 1, 1}}, // J
-	}
-	shape := shapes[rand.Intn(len(shapes))]
-	color := rand.Intn(len(colors)-1) + 1
-	return &Piece{x: cols/2 - len(shape[0])/2, y: 0, shape: shape, color: color}
+	// 标题、暂停、结束和高分画面
+	session *session.Session
+	// 显示得分事件
+	announcer render.Announcer
 }
 
 func (g *Game) Update() error {
-	if g.currentPiece == nil {
-		g.currentPiece = NewPiece()
-	}
-
 	// 控制方块移动
+	var in engine.Input
 	if ebiten.IsKeyPressed(ebiten.KeyLeft) {
-		g.currentPiece.x--
-		if g.collides() {
-			g.currentPiece.x++
-		}
+		in |= engine.InputLeft
 	}
 	if ebiten.IsKeyPressed(ebiten.KeyRight) {
-		g.currentPiece.x++
-		if g.collides() {
-			g.currentPiece.x--
-		}
+		in |= engine.InputRight
 	}
 	if ebiten.IsKeyPressed(ebiten.KeyDown) {
-		g.currentPiece.y++
-		if g.collides() {
-			g.currentPiece.y--
-		}
+		in |= engine.InputSoftDrop
 	}
 	if ebiten.IsKeyPressed(ebiten.KeyUp) {
-		g.rotatePiece()
+		in |= engine.InputRotateRight
 	}
-
-	// 控制方块下落速度
-	g.tick++
-	if g.tick >= g.speed {
-		g.tick = 0
-		g.currentPiece.y++
-		if g.collides() {
-			g.currentPiece.y--
-			g.mergePiece()
-			g.clearLines()
-			g.currentPiece = NewPiece()
-		}
+	if ebiten.IsKeyPressed(ebiten.KeyC) || ebiten.IsKeyPressed(ebiten.KeyShift) {
+		in |= engine.InputHold
 	}
-
+	if ebiten.IsKeyPressed(ebiten.KeySpace) {
+		in |= engine.InputHardDrop
+	}
+	g.announcer.Update(g.session.Update(render.SessionInput(in)))
 	return nil
 }
 
-func (g *Game) rotatePiece() {
-	originalShape := g.currentPiece.shape
-	rotatedShape := make([][]int, len(originalShape[0]))
-	for i := range rotatedShape {
-		rotatedShape[i] = make([]int, len(originalShape))
-	}
-	for y, row := range originalShape {
-		for x, cell := range row {
-			rotatedShape[x][len(originalShape)-1-y] = cell
-		}
-	}
-	g.currentPiece.shape = rotatedShape
-	if g.collides() {
-		g.currentPiece.shape = originalShape
+func (g *Game) Draw(screen *ebiten.Image) {
+	screen.Fill(color.Black)
+	// 暂停时不显示棋盘
+	if g.session.BoardVisible() {
+		g.drawGame(screen, g.session.Game)
 	}
+	render.Overlay(screen, g.session, 10, 120)
 }
 
-func (g *Game) collides() bool {
-	for y, row := range g.currentPiece.shape {
-		for x, cell := range row {
-			if cell != 0 {
-				boardX := g.currentPiece.x + x
-				boardY := g.currentPiece.y + y
-				if boardX < 0 || boardX >= cols || boardY >= rows || g.board[boardY][boardX] != 0 {
-					return true
-				}
-			}
-		}
-	}
-	return false
+func (g *Game) drawGame(screen *ebiten.Image, game *engine.Game) {
+	grid := render.Grid{Size: blockSize}
+	grid.Board(screen, game.Board)
+	grid.Current(screen, game)
+	render.Hold(screen, game, engine.BoardWidth*blockSize+5, 0, previewSize)
+	render.Next(screen, game, engine.BoardWidth*blockSize+5, 60, previewSize, 40)
+	g.announcer.Draw(screen, 5, 140)
+	ebitenutil.DebugPrint(screen, fmt.Sprintf("Score: %d", game.Score()))
+	render.Stats(screen, g.session, 5, 16)
 }
 
-func (g *Game) mergePiece() {
-	for y, row := range g.currentPiece.shape {
-		for x, cell := range row {
-			if cell != 0 {
-				g.board[g.currentPiece.y+y][g.currentPiece.x+x] = g.currentPiece.color
-			}
-		}
-	}
+func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
+	return screenWidth, screenHeight
 }
 
-func (g *Game) clearLines() {
-	for y := 0; y < rows; y++ {
-		full := true
-		for x := 0; x < cols; x++ {
-			if g.board[y][x] == 0 {
-				full = false
-				break
-			}
-		}
-		if full {
-			copy(g.board[1:y+1], g.board[0:y])
-			for x := 0; x < cols; x++ {
-				g.board[0][x] = 0
-			}
-		}
+func main() {
+	rules := engine.DefaultRules()
+	var seed int64
+	cli.RulesFlags(flag.CommandLine, &rules, &seed)
+	handling := engine.DefaultHandling()
+	cli.HandlingFlags(flag.CommandLine, &handling)
+	var bot cli.Bot
+	cli.BotFlags(flag.CommandLine, &bot)
+	flag.Parse()
+	if err := rules.Validate(); err != nil {
+		log.Fatal(err)
 	}
-}
-
-func (g *Game) Draw(screen *ebiten.Image) {
-	screen.Fill(colors[0])
-	for y, row := range g.board {
-		for x, cell := range row {
-			if cell != 0 {
-				ebitenutil.DrawRect(screen, float64(x*blockSize), float64(y*blockSize), blockSize, blockSize, colors[cell])
-			}
-		}
+	if err := handling.Validate(); err != nil {
+		log.Fatal(err)
+	}
+	scores, path, err := highscore.LoadDefault()
+	if err != nil {
+		log.Print(err)
 	}
-	for y, row := range g.currentPiece.shape {
-		for x, cell := range row {
-			if cell != 0 {
-				ebitenutil.DrawRect(screen, float64((g.currentPiece.x+x)*blockSize), float64((g.currentPiece.y+y)*blockSize), blockSize, blockSize, colors[g.currentPiece.color])
-			}
+	// 下落速度由等级决定
+	s := session.New(rules, handling, scores, path)
+	s.Seed = seed
+	if bot.Play {
+		if s.Bot, err = bot.Load(); err != nil {
+			log.Fatal(err)
 		}
 	}
-}
 
-func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
-	return screenWidth, screenHeight
-}
-
-func main() {
-	rand.Seed(time.Now().UnixNano())
+	ebiten.SetTPS(engine.TicksPerSecond)
 	ebiten.SetWindowSize(screenWidth, screenHeight)
 	ebiten.SetWindowTitle("Tetris")
-	game := &Game{speed: initialSpeed}
+	game := &Game{session: s}
 	if err := ebiten.RunGame(game); err != nil {
 		log.Fatal(err)
 	}
-}
\ No newline at end of file
+}
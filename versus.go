@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+
+	"tetris-game/engine"
+	"tetris-game/render"
+	"tetris-game/versus"
+)
+
+const (
+	versusWidth  = 2 * versusSide
+	versusHeight = versusTop + engine.BoardHeight*blockSize + 50
+
+	// Each player gets a side of the window: the garbage meter, the board
+	// and the hold and next panel.
+	versusSide  = meterWidth + 4 + engine.BoardWidth*blockSize + 10 + 70
+	versusTop   = 36
+	meterWidth  = 8
+	versusBoard = meterWidth + 4
+)
+
+// versusKeys are the bindings of the two players: WASD on the left and
+// the arrows on the right.
+var versusKeys = [2]map[ebiten.Key]engine.Input{
+	{
+		ebiten.KeyA:     engine.InputLeft,
+		ebiten.KeyD:     engine.InputRight,
+		ebiten.KeyS:     engine.InputSoftDrop,
+		ebiten.KeyW:     engine.InputRotateRight,
+		ebiten.KeyQ:     engine.InputRotateLeft,
+		ebiten.KeyE:     engine.InputHold,
+		ebiten.KeySpace: engine.InputHardDrop,
+	},
+	{
+		ebiten.KeyArrowLeft:  engine.InputLeft,
+		ebiten.KeyArrowRight: engine.InputRight,
+		ebiten.KeyArrowDown:  engine.InputSoftDrop,
+		ebiten.KeyArrowUp:    engine.InputRotateRight,
+		ebiten.KeySlash:      engine.InputRotateLeft,
+		ebiten.KeyShiftRight: engine.InputHold,
+		ebiten.KeyEnter:      engine.InputHardDrop,
+	},
+}
+
+var versusHelp = [2]string{
+	"A/D/S move  W/Q rotate  E hold  Space drop",
+	"Arrows  Up,/ rotate  RShift hold  Enter drop",
+}
+
+// Versus is a local two-player match: two games in one window, sharing
+// the layout.
+type Versus struct {
+	match      *versus.Match
+	announcers [2]render.Announcer
+
+	// Seed is used for every match; zero picks a new one each time.
+	seed     int64
+	rules    engine.Rules
+	handling engine.Handling
+}
+
+func NewVersus(seed int64, rules engine.Rules, handling engine.Handling) *Versus {
+	mode, _ := engine.LookupMode(engine.ModeEndless)
+	v := &Versus{seed: seed, rules: mode.Rules(rules), handling: handling}
+	v.start()
+	return v
+}
+
+// start begins a new match.
+func (v *Versus) start() {
+	seed := v.seed
+	if seed == 0 {
+		seed = engine.NewSeed()
+	}
+	v.match = versus.New(seed, v.rules)
+	for _, game := range v.match.Players {
+		game.SetHandling(v.handling)
+	}
+	v.announcers = [2]render.Announcer{}
+}
+
+func (v *Versus) Update() error {
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		return ebiten.Termination
+	}
+	if v.match.Over() {
+		if inpututil.IsKeyJustPressed(ebiten.KeyR) {
+			v.start()
+		}
+		return nil
+	}
+	var held [2]engine.Input
+	for i, keys := range versusKeys {
+		for key, in := range keys {
+			if ebiten.IsKeyPressed(key) {
+				held[i] |= in
+			}
+		}
+	}
+	for i, events := range v.match.Tick(held) {
+		v.announcers[i].Update(events)
+	}
+	return nil
+}
+
+func (v *Versus) Draw(screen *ebiten.Image) {
+	for i, game := range v.match.Players {
+		x := i * versusSide
+		boardX := x + versusBoard
+		ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Player %d  Score: %d", i+1, game.Score()), boardX, 2)
+		ebitenutil.DebugPrintAt(screen, versusHelp[i], x, 18)
+		render.GarbageMeter(screen, game, float64(x), versusTop, meterWidth, blockSize)
+
+		grid := render.Grid{X: float64(boardX), Y: versusTop, Size: blockSize}
+		grid.Board(screen, game.Board)
+		if !game.IsGameOver() {
+			grid.Current(screen, game)
+		}
+		panelX := float64(boardX + engine.BoardWidth*blockSize + 10)
+		render.Hold(screen, game, panelX, versusTop, previewSize)
+		render.Next(screen, game, panelX, versusTop+70, previewSize, 45)
+		v.announcers[i].Draw(screen, boardX, versusTop+engine.BoardHeight*blockSize+2)
+	}
+	if v.match.Over() {
+		result := "DRAW"
+		if w := v.match.Winner(); w != versus.Draw {
+			result = fmt.Sprintf("PLAYER %d WINS", w+1)
+		}
+		render.Message(screen, result+"\n\nR: rematch  Esc: quit", versusWidth/2-70, versusHeight/2-20)
+	}
+}
+
+func (v *Versus) Layout(outsideWidth, outsideHeight int) (int, int) {
+	return versusWidth, versusHeight
+}
@@ -0,0 +1,64 @@
+// Package versus runs a match between two games on one machine. The
+// garbage each player's clears send rises on the other's board, and the
+// last player standing wins.
+package versus
+
+import "tetris-game/engine"
+
+// Draw is the winner of a match both players lost on the same tick.
+const Draw = -1
+
+// Match is two games played side by side, dealt the same pieces.
+type Match struct {
+	Players [2]*engine.Game
+	over    bool
+	winner  int
+}
+
+// New starts a match in which both players play seed under rules.
+func New(seed int64, rules engine.Rules) *Match {
+	m := &Match{winner: Draw}
+	for i := range m.Players {
+		m.Players[i] = engine.NewGame(seed, rules)
+	}
+	return m
+}
+
+// Tick advances both games by one frame with the buttons each player
+// holds, sends the attacks of every lock to the opponent and returns the
+// events of both games.
+func (m *Match) Tick(held [2]engine.Input) [2][]engine.Event {
+	var events [2][]engine.Event
+	if m.over {
+		return events
+	}
+	for i, game := range m.Players {
+		game.Tick(held[i])
+		events[i] = game.Events()
+	}
+	for i, evs := range events {
+		for _, e := range evs {
+			m.Players[1-i].ReceiveGarbage(e.Attack)
+		}
+	}
+	lost := [2]bool{m.Players[0].IsGameOver(), m.Players[1].IsGameOver()}
+	switch {
+	case lost[0] && lost[1]:
+		m.over = true
+	case lost[0]:
+		m.over, m.winner = true, 1
+	case lost[1]:
+		m.over, m.winner = true, 0
+	}
+	return events
+}
+
+// Over reports whether a player has topped out.
+func (m *Match) Over() bool {
+	return m.over
+}
+
+// Winner returns the index of the player who won, or Draw.
+func (m *Match) Winner() int {
+	return m.winner
+}
@@ -0,0 +1,136 @@
+package versus
+
+import (
+	"testing"
+
+	"tetris-game/engine"
+)
+
+func testRules() engine.Rules {
+	rules := engine.DefaultRules()
+	rules.Randomizer = engine.RandomizerSequence
+	rules.Sequence = []engine.TetrominoType{engine.I}
+	rules.Gravity = engine.GravityFixed
+	rules.FixedG = 1.0 / 60
+	return rules
+}
+
+// board builds a board from its bottom rows, '#' for filled cells.
+func board(rows ...string) engine.Board {
+	b := engine.NewBoard()
+	top := engine.BoardHeight - len(rows)
+	for i, row := range rows {
+		for x, c := range row {
+			if c == '#' {
+				b[top+i][x] = engine.Garbage
+			}
+		}
+	}
+	return b
+}
+
+// setWell gives game a well four rows deep above a row the Tetris leaves
+// behind, with its I hanging over it.
+func setWell(game *engine.Game) {
+	game.Board = board(
+		"#########.",
+		"#########.",
+		"#########.",
+		"#########.",
+		"####.#####",
+	)
+	game.CurrentTetromino = &engine.Tetromino{Type: engine.I, Rotation: engine.R90, X: 7, Y: 0}
+}
+
+// setFull fills game's board up to just below its piece, so that the
+// next piece cannot spawn once it locks.
+func setFull(game *engine.Game) {
+	rows := make([]string, engine.BoardHeight-2)
+	for i := range rows {
+		rows[i] = "#########."
+	}
+	game.Board = board(rows...)
+}
+
+func TestTetrisSendsGarbage(t *testing.T) {
+	tests := []struct {
+		name        string
+		incoming    int
+		wantAttack  int
+		wantPending int
+	}{
+		{"sent", 0, 4, 4},
+		{"partly canceled", 3, 1, 1},
+		{"canceled", 4, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := New(1, testRules())
+			setWell(m.Players[0])
+			m.Players[0].ReceiveGarbage(tt.incoming)
+			events := m.Tick([2]engine.Input{engine.InputHardDrop, 0})
+			attack := 0
+			for _, e := range events[0] {
+				attack += e.Attack
+			}
+			if attack != tt.wantAttack {
+				t.Errorf("attack %d, want %d", attack, tt.wantAttack)
+			}
+			if got := m.Players[0].PendingGarbage(); got != 0 {
+				t.Errorf("%d lines still pending on the sender", got)
+			}
+			if got := m.Players[1].PendingGarbage(); got != tt.wantPending {
+				t.Errorf("%d lines pending on the opponent, want %d", got, tt.wantPending)
+			}
+			if m.Over() {
+				t.Error("match over")
+			}
+		})
+	}
+}
+
+func TestWinner(t *testing.T) {
+	tests := []struct {
+		name string
+		full [2]bool
+		want int
+	}{
+		{"first tops out", [2]bool{true, false}, 1},
+		{"second tops out", [2]bool{false, true}, 0},
+		{"both top out", [2]bool{true, true}, Draw},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := New(1, testRules())
+			for i, full := range tt.full {
+				if full {
+					setFull(m.Players[i])
+				}
+			}
+			m.Tick([2]engine.Input{engine.InputHardDrop, engine.InputHardDrop})
+			if !m.Over() {
+				t.Fatal("match not over")
+			}
+			if got := m.Winner(); got != tt.want {
+				t.Errorf("winner %d, want %d", got, tt.want)
+			}
+			if events := m.Tick([2]engine.Input{}); events[0] != nil || events[1] != nil {
+				t.Error("ticked after the match ended")
+			}
+		})
+	}
+}
+
+func TestNotOver(t *testing.T) {
+	m := New(1, testRules())
+	for range 3 {
+		m.Tick([2]engine.Input{engine.InputHardDrop, engine.InputHardDrop})
+		m.Tick([2]engine.Input{})
+	}
+	if m.Over() || m.Winner() != Draw {
+		t.Errorf("over %v, winner %d after three pieces each", m.Over(), m.Winner())
+	}
+	if m.Players[0].Pieces() != 3 || m.Players[1].Pieces() != 3 {
+		t.Errorf("placed %d and %d pieces, want 3", m.Players[0].Pieces(), m.Players[1].Pieces())
+	}
+}
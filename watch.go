@@ -0,0 +1,106 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+
+	"tetris-game/engine"
+	"tetris-game/render"
+	"tetris-game/spectate"
+)
+
+// Watch shows a game published by another instance. It only reads.
+type Watch struct {
+	viewer    *spectate.Viewer
+	announcer render.Announcer
+	addr      string
+}
+
+func (w *Watch) Update() error {
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		return ebiten.Termination
+	}
+	w.announcer.Update(w.viewer.Events())
+	return nil
+}
+
+func (w *Watch) Draw(screen *ebiten.Image) {
+	state, _, ok := w.viewer.State()
+	if err := w.viewer.Err(); err != nil {
+		render.Message(screen, err.Error()+"\n\nEsc: quit", 10, 40)
+		return
+	}
+	if !ok {
+		render.Message(screen, "Waiting for "+w.addr+"\n\nEsc: quit", 10, 40)
+		return
+	}
+	board := state.DecodeBoard()
+	grid := render.Grid{Size: blockSize}
+	grid.Board(screen, board)
+	if !state.Over {
+		ghost := board.Drop(state.Piece)
+		clr := render.PieceColors[state.Piece.Type]
+		grid.GhostPiece(screen, &ghost, clr)
+		grid.Tetromino(screen, &state.Piece, clr)
+	}
+
+	ebitenutil.DebugPrintAt(screen, "HOLD", panelX, 0)
+	for _, t := range state.Hold {
+		render.Piece(screen, t, panelX, 20, previewSize, render.PieceColors[t])
+	}
+	if len(state.Next) > 0 {
+		ebitenutil.DebugPrintAt(screen, "NEXT", panelX, 70)
+		for i, t := range state.Next {
+			render.Piece(screen, t, panelX, float64(90+i*45), previewSize, render.PieceColors[t])
+		}
+	}
+	w.announcer.Draw(screen, 0, engine.BoardHeight*blockSize+4)
+
+	status := []string{fmt.Sprintf("Score: %d\nLevel: %d", state.Score, state.Level), "Watching " + w.addr}
+	if state.Over {
+		status = append(status, "GAME OVER")
+	}
+	ebitenutil.DebugPrint(screen, strings.Join(status, "\n"))
+}
+
+func (w *Watch) Layout(outsideWidth, outsideHeight int) (int, int) {
+	return screenWidth, screenHeight
+}
+
+// watch runs "tetris watch host:port": a read-only view of a game started
+// with -publish.
+func watch(args []string) {
+	fs := flag.NewFlagSet("watch", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: tetris watch host[:port]")
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	addr := fs.Arg(0)
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, spectate.DefaultPort)
+	}
+	viewer, err := spectate.Watch(addr)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer viewer.Close()
+
+	ebiten.SetTPS(engine.TicksPerSecond)
+	ebiten.SetWindowSize(screenWidth, screenHeight)
+	ebiten.SetWindowTitle("Tetris - watching " + addr)
+	if err := ebiten.RunGame(&Watch{viewer: viewer, addr: addr}); err != nil {
+		log.Fatal(err)
+	}
+}